@@ -12,6 +12,8 @@ package asserts // import "tideland.dev/go/audit/asserts"
 //--------------------
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -24,13 +26,15 @@ import (
 
 // Asserts provides a number of convenient test methods.
 type Asserts struct {
-	failer Failer
+	failer    Failer
+	snapshots *snapshots
 }
 
 // New creates a new Asserts instance.
 func New(f Failer) *Asserts {
 	return &Asserts{
-		failer: f,
+		failer:    f,
+		snapshots: newSnapshots(),
 	}
 }
 
@@ -166,6 +170,20 @@ func (a *Asserts) Equal(obtained, expected any, msgs ...string) bool {
 	return true
 }
 
+// EqualWith tests if obtained and expected are equal with the passed
+// options in force. They allow e.g. to ignore fields or to compare
+// floats within a tolerance. The option Message() sets the message
+// of a failure.
+//
+//	assert.EqualWith(obtained, expected, asserts.IgnoreFields("ID"), asserts.UnorderedSlices(), asserts.Message("order"))
+func (a *Asserts) EqualWith(obtained, expected any, opts ...EqualOption) bool {
+	eo := newEqualOptions(opts...)
+	if !isEqualWith(obtained, expected, eo) {
+		return a.failer.Fail(EqualWith, obtained, &optionsValue{expected, eo}, eo.msgs...)
+	}
+	return true
+}
+
 // Different tests if obtained and expected are different.
 func (a *Asserts) Different(obtained, expected any, msgs ...string) bool {
 	if isEqual(obtained, expected) {
@@ -221,6 +239,38 @@ func (a *Asserts) ErrorContains(obtained any, part string, msgs ...string) bool
 	return true
 }
 
+// ErrorIs tests if the obtained error or ErrorProne.Err() matches the
+// target by using errors.Is(). In case of a failure the chain of wrapped
+// errors is shown.
+func (a *Asserts) ErrorIs(obtained any, target error, msgs ...string) bool {
+	if obtained == nil {
+		return a.failer.Fail(ErrorIs, nil, target, appendMsg(msgs, "error is nil")...)
+	}
+	err := anyToError(obtained)
+	if !errors.Is(err, target) {
+		return a.failer.Fail(ErrorIs, err, target, msgs...)
+	}
+	return true
+}
+
+// ErrorAs tests if the obtained error or ErrorProne.Err() can be assigned
+// to the target by using errors.As(). Target has to be a non-nil pointer to
+// an error type or interface. In case of a failure the chain of wrapped errors
+// is shown.
+func (a *Asserts) ErrorAs(obtained any, target any, msgs ...string) bool {
+	if obtained == nil {
+		return a.failer.Fail(ErrorAs, nil, ValueDescription(target), appendMsg(msgs, "error is nil")...)
+	}
+	err := anyToError(obtained)
+	if terr := isErrorTarget(target); terr != nil {
+		return a.failer.Fail(ErrorAs, err, ValueDescription(target), appendMsg(msgs, terr.Error())...)
+	}
+	if !errors.As(err, target) {
+		return a.failer.Fail(ErrorAs, err, ValueDescription(target), msgs...)
+	}
+	return true
+}
+
 // Contains tests if the obtained data is part of the expected
 // string, array, or slice.
 func (a *Asserts) Contains(part, full any, msgs ...string) bool {
@@ -247,6 +297,141 @@ func (a *Asserts) NotContains(part, full any, msgs ...string) bool {
 	return true
 }
 
+// ContainsAll tests if the obtained array or slice contains all
+// elements of the expected array or slice. The failure lists the
+// missing elements.
+func (a *Asserts) ContainsAll(obtained, expected any, msgs ...string) bool {
+	missing, _, err := unmatchedElements(obtained, expected, false)
+	if err != nil {
+		return a.failer.Fail(ContainsAll, obtained, expected, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if len(missing) > 0 {
+		return a.failer.Fail(ContainsAll, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// ContainsAny tests if the obtained array or slice contains at least
+// one element of the expected array or slice.
+func (a *Asserts) ContainsAny(obtained, expected any, msgs ...string) bool {
+	missing, _, err := unmatchedElements(obtained, expected, false)
+	if err != nil {
+		return a.failer.Fail(ContainsAny, obtained, expected, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if es, _ := elementsOf(expected); len(missing) == len(es) {
+		return a.failer.Fail(ContainsAny, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// ElementsMatch tests if the obtained and expected arrays or slices
+// contain the same elements regardless of their order. Each element
+// has to be contained as often as in the other one. The failure lists
+// the missing and the extra elements.
+func (a *Asserts) ElementsMatch(obtained, expected any, msgs ...string) bool {
+	missing, extra, err := unmatchedElements(obtained, expected, true)
+	if err != nil {
+		return a.failer.Fail(ElementsMatch, obtained, expected, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if len(missing) > 0 || len(extra) > 0 {
+		return a.failer.Fail(ElementsMatch, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// Subset tests if all elements of the obtained array or slice are
+// contained in the full array or slice. The failure lists the extra
+// elements.
+func (a *Asserts) Subset(obtained, full any, msgs ...string) bool {
+	_, extra, err := unmatchedElements(obtained, full, false)
+	if err != nil {
+		return a.failer.Fail(Subset, obtained, full, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if len(extra) > 0 {
+		return a.failer.Fail(Subset, obtained, full, msgs...)
+	}
+	return true
+}
+
+// Sorted tests if the elements of the obtained array or slice, numbers
+// or strings, are sorted in ascending order. The failure names the first
+// element out of order. See SortedByT() for sorting by a key.
+func (a *Asserts) Sorted(obtained any, msgs ...string) bool {
+	index, err := unsortedIndex(obtained)
+	if err != nil {
+		return a.failer.Fail(Sorted, obtained, nil, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if index >= 0 {
+		return a.failer.Fail(Sorted, obtained, index, msgs...)
+	}
+	return true
+}
+
+// Unique tests if the obtained array or slice contains no duplicate
+// elements. The failure lists the duplicates.
+func (a *Asserts) Unique(obtained any, msgs ...string) bool {
+	duplicates, err := duplicateElements(obtained)
+	if err != nil {
+		return a.failer.Fail(Unique, obtained, nil, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if len(duplicates) > 0 {
+		return a.failer.Fail(Unique, obtained, nil, msgs...)
+	}
+	return true
+}
+
+// HasKey tests if the obtained map contains the key.
+func (a *Asserts) HasKey(obtained, key any, msgs ...string) bool {
+	ok, err := hasKey(obtained, key)
+	if err != nil {
+		return a.failer.Fail(HasKey, obtained, key, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if !ok {
+		return a.failer.Fail(HasKey, obtained, key, msgs...)
+	}
+	return true
+}
+
+// HasValue tests if the obtained map contains the value for any key.
+func (a *Asserts) HasValue(obtained, value any, msgs ...string) bool {
+	ok, err := hasValue(obtained, value)
+	if err != nil {
+		return a.failer.Fail(HasValue, obtained, value, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if !ok {
+		return a.failer.Fail(HasValue, obtained, value, msgs...)
+	}
+	return true
+}
+
+// KeysEqual tests if the obtained map has exactly the expected keys.
+// They can be passed as array or slice or as map with the same key type.
+// The failure lists the missing and the unexpected keys.
+func (a *Asserts) KeysEqual(obtained, expected any, msgs ...string) bool {
+	diff, err := keysDiff(obtained, expected)
+	if err != nil {
+		return a.failer.Fail(KeysEqual, obtained, expected, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if len(diff) > 0 {
+		return a.failer.Fail(KeysEqual, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// MapSubset tests if the obtained map contains all entries of the expected
+// map with equal values. Additional entries of obtained are allowed. The
+// failure lists the missing keys and the differing values with their paths.
+func (a *Asserts) MapSubset(obtained, expected any, msgs ...string) bool {
+	diff, err := mapSubsetDiff(obtained, expected)
+	if err != nil {
+		return a.failer.Fail(MapSubset, obtained, expected, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if len(diff) > 0 {
+		return a.failer.Fail(MapSubset, obtained, expected, msgs...)
+	}
+	return true
+}
+
 // About tests if obtained and expected are near to each other
 // (within the given extent).
 func (a *Asserts) About(obtained, expected, extent float64, msgs ...string) bool {
@@ -257,9 +442,9 @@ func (a *Asserts) About(obtained, expected, extent float64, msgs ...string) bool
 }
 
 // Range tests if obtained is larger or equal low and lower or
-// equal high. Allowed are byte, int and float64 for numbers, runes,
-// strings, times, and duration. In case of obtained arrays,
-// slices, and maps low and high have to be ints for testing
+// equal high. Allowed are numbers of all kinds, which also may be
+// mixed, runes, strings, times, and duration. In case of obtained
+// arrays, slices, and maps low and high have to be ints for testing
 // the length.
 func (a *Asserts) Range(obtained, low, high any, msgs ...string) bool {
 	expected := &lowHigh{low, high}
@@ -383,10 +568,12 @@ func (a *Asserts) Panics(pf func(), msgs ...string) bool {
 	return true
 }
 
-// NotPanics checks if the passed function does not panic.
+// NotPanics checks if the passed function does not panic. In case of
+// a panic the failure contains a PanicInfo with the recovered value
+// and the stack of the panicking goroutine.
 func (a *Asserts) NotPanics(pf func(), msgs ...string) bool {
-	if hasPanic(pf, nil) {
-		return a.failer.Fail(NotPanics, ValueDescription(pf), nil, msgs...)
+	if pi := recoverPanic(pf); pi != nil {
+		return a.failer.Fail(NotPanics, pi, nil, msgs...)
 	}
 	return true
 }
@@ -399,6 +586,42 @@ func (a *Asserts) PanicsWith(pf func(), reason any, msgs ...string) bool {
 	return true
 }
 
+// PanicsMatching checks if the passed function panics with a reason
+// matching the regular expression. Errors are matched by their message,
+// other reasons by their formatting with %v.
+func (a *Asserts) PanicsMatching(pf func(), regex string, msgs ...string) bool {
+	pi := recoverPanic(pf)
+	if pi == nil {
+		return a.failer.Fail(PanicsMatching, ValueDescription(pf), regex, msgs...)
+	}
+	matches, err := isMatching(pi.reason(), regex)
+	if err != nil {
+		return a.failer.Fail(PanicsMatching, pi.Value, regex, appendMsg(msgs, "can't compile regex: "+err.Error())...)
+	}
+	if !matches {
+		return a.failer.Fail(PanicsMatching, pi.Value, regex, msgs...)
+	}
+	return true
+}
+
+// PanicsWithError checks if the passed function panics with an error
+// matching the target by using errors.Is(). In case of a failure the
+// chain of wrapped errors is shown.
+func (a *Asserts) PanicsWithError(pf func(), target error, msgs ...string) bool {
+	pi := recoverPanic(pf)
+	if pi == nil {
+		return a.failer.Fail(PanicsWithError, ValueDescription(pf), target, msgs...)
+	}
+	err, ok := pi.Value.(error)
+	if !ok {
+		return a.failer.Fail(PanicsWithError, pi.Value, target, appendMsg(msgs, "reason is no error")...)
+	}
+	if !errors.Is(err, target) {
+		return a.failer.Fail(PanicsWithError, err, target, msgs...)
+	}
+	return true
+}
+
 // PathExists checks if the passed path or file exists.
 func (a *Asserts) PathExists(obtained string, msgs ...string) bool {
 	valid, err := isValidPath(obtained)
@@ -419,15 +642,35 @@ func (a *Asserts) Wait(
 	timeout time.Duration,
 	msgs ...string,
 ) bool {
-	select {
-	case obtained := <-sigc:
-		if !isEqual(obtained, expected) {
-			return a.failer.Fail(Wait, obtained, expected, msgs...)
-		}
-		return true
-	case <-time.After(timeout):
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	obtained, ok := receiveSignal(ctx, sigc)
+	if !ok {
 		return a.failer.Fail(Wait, "timeout "+timeout.String(), "signal true", msgs...)
 	}
+	if !isEqual(obtained, expected) {
+		return a.failer.Fail(Wait, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// WaitCtx works like Wait but waits until the passed context is done.
+// A failure tells if it has been cancelled or its deadline exceeded.
+func (a *Asserts) WaitCtx(
+	ctx context.Context,
+	sigc <-chan any,
+	expected any,
+	msgs ...string,
+) bool {
+	start := time.Now()
+	obtained, ok := receiveSignal(ctx, sigc)
+	if !ok {
+		return a.failer.Fail(Wait, contextReason(ctx, start), "signal true", msgs...)
+	}
+	if !isEqual(obtained, expected) {
+		return a.failer.Fail(Wait, obtained, expected, msgs...)
+	}
+	return true
 }
 
 // WaitClosed waits until a channel closing, the assert fails on a timeout.
@@ -436,42 +679,58 @@ func (a *Asserts) WaitClosed(
 	timeout time.Duration,
 	msgs ...string,
 ) bool {
-	done := time.NewTimer(timeout)
-	defer done.Stop()
-	for {
-		select {
-		case _, ok := <-sigc:
-			if !ok {
-				// Only return true if channel has been closed.
-				return true
-			}
-		case <-done.C:
-			return a.failer.Fail(WaitClosed, "timeout "+timeout.String(), "closed", msgs...)
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if !waitClosed(ctx, sigc) {
+		return a.failer.Fail(WaitClosed, "timeout "+timeout.String(), "closed", msgs...)
+	}
+	return true
+}
+
+// WaitClosedCtx works like WaitClosed but waits until the passed context
+// is done. A failure tells if it has been cancelled or its deadline exceeded.
+func (a *Asserts) WaitClosedCtx(
+	ctx context.Context,
+	sigc <-chan any,
+	msgs ...string,
+) bool {
+	start := time.Now()
+	if !waitClosed(ctx, sigc) {
+		return a.failer.Fail(WaitClosed, contextReason(ctx, start), "closed", msgs...)
 	}
+	return true
 }
 
 // WaitGroup waits until a wait group instance is done, the assert fails on a timeout.
+// Waiting for a wait group cannot be interrupted, so after a timeout one goroutine
+// per group stays parked until the group finishes. Later waits for the same group
+// share it.
 func (a *Asserts) WaitGroup(
 	wg *sync.WaitGroup,
 	timeout time.Duration,
 	msgs ...string,
 ) bool {
-	stopc := make(chan struct{}, 1)
-	done := time.NewTimer(timeout)
-	defer done.Stop()
-	go func() {
-		wg.Wait()
-		stopc <- struct{}{}
-	}()
-	for {
-		select {
-		case <-stopc:
-			return true
-		case <-done.C:
-			return a.failer.Fail(WaitGroup, "timeout "+timeout.String(), "done", msgs...)
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if !waitGroup(ctx, wg) {
+		return a.failer.Fail(WaitGroup, "timeout "+timeout.String(), "done", msgs...)
+	}
+	return true
+}
+
+// WaitGroupCtx works like WaitGroup but waits until the passed context
+// is done. A failure tells if it has been cancelled or its deadline exceeded.
+// Like with WaitGroup one goroutine per group stays parked until it finishes.
+func (a *Asserts) WaitGroupCtx(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	msgs ...string,
+) bool {
+	start := time.Now()
+	if !waitGroup(ctx, wg) {
+		return a.failer.Fail(WaitGroup, contextReason(ctx, start), "done", msgs...)
 	}
+	return true
 }
 
 // WaitTested receives a signal from a channel and runs the passed tester
@@ -483,30 +742,131 @@ func (a *Asserts) WaitTested(
 	timeout time.Duration,
 	msgs ...string,
 ) bool {
-	select {
-	case obtained := <-sigc:
-		err := test(obtained)
-		return a.Nil(err, msgs...)
-	case <-time.After(timeout):
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	obtained, ok := receiveSignal(ctx, sigc)
+	if !ok {
 		return a.failer.Fail(WaitTested, "timeout "+timeout.String(), "signal tested", msgs...)
 	}
+	err := test(obtained)
+	return a.Nil(err, msgs...)
+}
+
+// WaitTestedCtx works like WaitTested but waits until the passed context
+// is done. A failure tells if it has been cancelled or its deadline exceeded.
+func (a *Asserts) WaitTestedCtx(
+	ctx context.Context,
+	sigc <-chan any,
+	test func(any) error,
+	msgs ...string,
+) bool {
+	start := time.Now()
+	obtained, ok := receiveSignal(ctx, sigc)
+	if !ok {
+		return a.failer.Fail(WaitTested, contextReason(ctx, start), "signal tested", msgs...)
+	}
+	err := test(obtained)
+	return a.Nil(err, msgs...)
 }
 
 // Retry calls the passed function and expects it to return true. Otherwise
 // it pauses for the given duration and retries the call the defined number.
 func (a *Asserts) Retry(rf func() bool, retries int, pause time.Duration, msgs ...string) bool {
 	start := time.Now()
-	for r := 0; r < retries; r++ {
-		if rf() {
-			return true
-		}
-		time.Sleep(pause)
+	if _, ok := retry(context.Background(), rf, retries, pause); ok {
+		return true
 	}
 	needed := time.Since(start)
 	info := fmt.Sprintf("timeout after %v and %d retries", needed, retries)
 	return a.failer.Fail(Retry, info, "successful call", msgs...)
 }
 
+// RetryCtx works like Retry but stops retrying when the passed context
+// is done. A failure tells if it has been cancelled or its deadline
+// exceeded, and the number of retries.
+func (a *Asserts) RetryCtx(ctx context.Context, rf func() bool, retries int, pause time.Duration, msgs ...string) bool {
+	start := time.Now()
+	done, ok := retry(ctx, rf, retries, pause)
+	if ok {
+		return true
+	}
+	if ctx.Err() != nil {
+		info := fmt.Sprintf("%s and %d retries", contextReason(ctx, start), done)
+		return a.failer.Fail(Retry, info, "successful call", msgs...)
+	}
+	info := fmt.Sprintf("timeout after %v and %d retries", time.Since(start), done)
+	return a.failer.Fail(Retry, info, "successful call", msgs...)
+}
+
+// Eventually calls the passed function in the given interval until it
+// returns no error. Otherwise the assert fails after the timeout. The
+// failure tells the number of attempts, the elapsed time, and the last
+// returned error.
+func (a *Asserts) Eventually(cf func() error, timeout, interval time.Duration, msgs ...string) bool {
+	pr := pollEventually(context.Background(), cf, timeout, interval)
+	if pr.err != nil {
+		return a.failer.Fail(Eventually, pr, "no error", msgs...)
+	}
+	return true
+}
+
+// EventuallyCtx works like Eventually but can be cancelled by the
+// passed context too.
+func (a *Asserts) EventuallyCtx(ctx context.Context, cf func() error, timeout, interval time.Duration, msgs ...string) bool {
+	pr := pollEventually(ctx, cf, timeout, interval)
+	if pr.err != nil {
+		return a.failer.Fail(Eventually, pr, "no error", msgs...)
+	}
+	return true
+}
+
+// Consistently calls the passed function in the given interval for the
+// given duration. The assert fails as soon as it returns an error. The
+// failure tells the number of attempts, the elapsed time, and the
+// returned error.
+func (a *Asserts) Consistently(cf func() error, duration, interval time.Duration, msgs ...string) bool {
+	pr := pollConsistently(context.Background(), cf, duration, interval)
+	if pr.err != nil {
+		return a.failer.Fail(Consistently, pr, "no error", msgs...)
+	}
+	return true
+}
+
+// ConsistentlyCtx works like Consistently but can be cancelled by the
+// passed context too. A cancellation lets the assert fail.
+func (a *Asserts) ConsistentlyCtx(ctx context.Context, cf func() error, duration, interval time.Duration, msgs ...string) bool {
+	pr := pollConsistently(ctx, cf, duration, interval)
+	if pr.err != nil {
+		return a.failer.Fail(Consistently, pr, "no error", msgs...)
+	}
+	return true
+}
+
+// Group runs the passed function with an Asserts instance collecting
+// all failures instead of reacting on each one. Afterwards they are
+// reported together as one failure of the group. So the fail mode of
+// the parent is only respected once at the end.
+//
+//	assert.Group("user fields", func(g *asserts.Asserts) {
+//	    g.Equal(user.Name, "Alice")
+//	    g.Equal(user.Age, 42)
+//	})
+func (a *Asserts) Group(name string, gf func(g *Asserts), msgs ...string) bool {
+	gfailer := &groupFailer{
+		parent: a.failer,
+		offset: 4,
+	}
+	gf(&Asserts{
+		failer:    gfailer,
+		snapshots: a.snapshots,
+	})
+	details := gfailer.collected()
+	if len(details) > 0 {
+		return a.failer.Fail(Group, details, name, msgs...)
+	}
+	return true
+}
+
 // Logf can be used to display helpful information during testing.
 func (a *Asserts) Logf(format string, as ...any) {
 	a.failer.Logf(format, as...)
@@ -523,6 +883,24 @@ func (a *Asserts) Failf(format string, as ...any) bool {
 	return a.failer.Fail(Fail, nil, nil, msg)
 }
 
+// Report lets own assertions report a failure of their test registered
+// with RegisterTest(). It has to be called directly by the assertion
+// function so that the location of the failure is the caller of it.
+//
+//	var Even = asserts.RegisterTest("even")
+//
+//	func IsEven(a *asserts.Asserts, obtained int, msgs ...string) bool {
+//		if obtained%2 != 0 {
+//			return a.Report(Even, obtained, nil, msgs...)
+//		}
+//		return true
+//	}
+func (a *Asserts) Report(test Test, obtained, expected any, msgs ...string) bool {
+	restore := a.failer.IncrCallstackOffset()
+	defer restore()
+	return a.failer.Fail(test, obtained, expected, msgs...)
+}
+
 // MakeWaitChan is a simple one-liner to create the buffered signal channel
 // for the wait assertion.
 func MakeWaitChan() chan any {
@@ -581,23 +959,87 @@ type lenable interface {
 }
 
 // obexString constructs a descriptive sting matching
-// to test, obtained, and expected value.
-func obexString(test Test, obtained, expected any) string {
+// to test, obtained, and expected value. A given diff
+// replaces the both values.
+func obexString(test Test, obtained, expected any, diff []string) string {
+	if len(diff) > 0 {
+		switch test {
+		case ErrorIs, ErrorAs, PanicsWithError:
+			return fmt.Sprintf("'%v' <> '%v' with chain %s", obtained, expected, strings.Join(diff, "; "))
+		case NotPanics:
+			if pi, ok := obtained.(*PanicInfo); ok {
+				return fmt.Sprintf("panic '%v' in %s", pi.Value, pi.topFrame())
+			}
+		case Sorted, Increasing, Decreasing:
+			return fmt.Sprintf("'%v' is not %v: %s", obtained, test, strings.Join(diff, "; "))
+		case Unique:
+			return fmt.Sprintf("'%v' has duplicates: %s", obtained, strings.Join(diff, "; "))
+		case GoroutineLeak:
+			stacks, _ := obtained.([]string)
+			return fmt.Sprintf("%d goroutine(s) leaked after '%v': %s", len(stacks), expected, strings.Join(diff, "; "))
+		}
+		if isText(obtained, expected) {
+			return fmt.Sprintf("multi-line strings differ in %d line(s)", changedLines(diff))
+		}
+		out := "differences " + strings.Join(diff, "; ")
+		if ov, ok := expected.(*optionsValue); ok {
+			out += " with options " + ov.options.String()
+		}
+		return out
+	}
 	switch test {
-	case True, False, Nil, NotNil, Empty, NotEmpty:
+	case True, False, Nil, NotNil, Empty, NotEmpty, Positive, Negative, NaN:
 		return fmt.Sprintf("'%v'", obtained)
 	case Implementor, Assignable, Unassignable:
 		return fmt.Sprintf("'%v' <> '%v'", ValueDescription(obtained), ValueDescription(expected))
 	case Range:
 		lh := expected.(*lowHigh)
 		return fmt.Sprintf("not '%v' <= '%v' <= '%v'", lh.low, obtained, lh.high)
+	case Group:
+		details, _ := obtained.([]FailureDetail)
+		errs := []string{}
+		for i, detail := range details {
+			errs = append(errs, fmt.Sprintf("[%d] %v", i, detail.Error()))
+		}
+		return fmt.Sprintf("'%v' has %d failure(s): %s", expected, len(details), strings.Join(errs, " / "))
+	case TimeEqual:
+		return fmt.Sprintf("'%s' <> '%s'", timeString(obtained), timeString(expected))
+	case WithinDuration:
+		return fmt.Sprintf("'%s' is not within '%s'", timeString(obtained), timeString(expected))
+	case Before, After:
+		return fmt.Sprintf("'%s' is not %v '%s'", timeString(obtained), test, timeString(expected))
+	case SameSecond, SameMinute, SameDay:
+		return fmt.Sprintf("'%s' is not in the %v as '%s'", timeString(obtained), test, timeString(expected))
+	case HasKey:
+		return fmt.Sprintf("'%v' has no key '%v'", obtained, expected)
+	case HasValue:
+		return fmt.Sprintf("'%v' has no value '%v'", obtained, expected)
+	case ContainsAny:
+		return fmt.Sprintf("'%v' contains none of '%v'", obtained, expected)
+	case HasPrefix:
+		return fmt.Sprintf("'%v' has no prefix '%v'", obtained, expected)
+	case HasSuffix:
+		return fmt.Sprintf("'%v' has no suffix '%v'", obtained, expected)
+	case Unique:
+		return fmt.Sprintf("'%v' has duplicates", obtained)
+	case Sorted:
+		return fmt.Sprintf("'%v' is not sorted at index %v", obtained, expected)
 	case Fail:
 		return "fail intended"
 	default:
+		if format := testFormatter(test); format != nil {
+			return format(obtained, expected)
+		}
 		return fmt.Sprintf("'%v' <> '%v'", obtained, expected)
 	}
 }
 
+// appendMsg returns the messages of the caller extended by
+// the reason of a failure not caused by the test itself.
+func appendMsg(msgs []string, reason string) []string {
+	return append(append([]string{}, msgs...), reason)
+}
+
 // failString constructs a fail string for panics or
 // validition errors.
 func failString(test Test, obex string, msgs ...string) string {
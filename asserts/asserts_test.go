@@ -12,10 +12,18 @@ package asserts_test
 //--------------------
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -546,6 +554,17 @@ func TestAssertWaitGroup(t *testing.T) {
 		}
 	}()
 	failingAssert.WaitGroup(&wg, 200*time.Millisecond, "should timeout")
+
+	// Repeated timeouts share one waiting goroutine.
+	var pendingWG sync.WaitGroup
+	pendingWG.Add(1)
+	failingAssert.WaitGroup(&pendingWG, time.Millisecond)
+	snapshot := asserts.NewGoroutineSnapshot()
+	failingAssert.WaitGroup(&pendingWG, time.Millisecond)
+	failingAssert.WaitGroup(&pendingWG, time.Millisecond)
+	successfulAssert.NoNewGoroutines(snapshot, 0, nil)
+	pendingWG.Done()
+	successfulAssert.WaitGroup(&pendingWG, time.Second)
 }
 
 // TestAssertWaitTested tests the wait tested testing.
@@ -654,6 +673,7 @@ func TestValidationAssertion(t *testing.T) {
 	assert.True(true, "should not fail")
 	assert.True(false, "should fail")
 	assert.Equal(1, 2, "should fail")
+	trueLocation, equalLocation := callerLocation(-2), callerLocation(-1)
 
 	if !failures.HasErrors() {
 		t.Errorf("should have errors")
@@ -668,7 +688,7 @@ func TestValidationAssertion(t *testing.T) {
 	details := failures.Details()
 	location, fun := details[0].Location()
 	tt := details[0].Test()
-	if location != "asserts_test.go:655:0:" || fun != "TestValidationAssertion" {
+	if location != trueLocation || fun != "TestValidationAssertion" {
 		t.Errorf("wrong location %q or function %q of first detail", location, fun)
 	}
 	if tt != asserts.True {
@@ -676,7 +696,7 @@ func TestValidationAssertion(t *testing.T) {
 	}
 	location, fun = details[1].Location()
 	tt = details[1].Test()
-	if location != "asserts_test.go:656:0:" || fun != "TestValidationAssertion" {
+	if location != equalLocation || fun != "TestValidationAssertion" {
 		t.Errorf("wrong location %q or function %q of second detail", location, fun)
 	}
 	if tt != asserts.Equal {
@@ -725,6 +745,1258 @@ func TestSetPrinter(t *testing.T) {
 	assert.Length(b, 0)
 }
 
+// TestEqualDiff tests the structural diff of failing Equal() assertions.
+func TestEqualDiff(t *testing.T) {
+	type item struct {
+		Qty   int
+		Price float64
+	}
+	type order struct {
+		ID    string
+		Items map[string]item
+	}
+	type customer struct {
+		Name   string
+		Orders []order
+		Tags   []string
+		note   string
+	}
+	obtained := customer{
+		Name: "Alice",
+		Orders: []order{
+			{ID: "a", Items: map[string]item{"sku": {2, 9.99}}},
+			{ID: "b", Items: map[string]item{"foo": {1, 1.0}}},
+		},
+		Tags: []string{"x"},
+		note: "one",
+	}
+	expected := customer{
+		Name: "Alice",
+		Orders: []order{
+			{ID: "a", Items: map[string]item{"sku": {3, 9.99}}},
+			{ID: "b", Items: map[string]item{"bar": {1, 1.0}}},
+		},
+		note: "two",
+	}
+
+	assert, failures := asserts.NewValidation()
+	assert.Equal(obtained, expected, "should fail with diff")
+	assert.Equal(1, 2, "should fail without diff")
+
+	details := failures.Details()
+	if len(details) != 2 {
+		t.Fatalf("wrong number of details: %d", len(details))
+	}
+	diff := details[0].Diff()
+	expectedDiff := []string{
+		`.Orders[0].Items["sku"].Qty: got 2, want 3`,
+		`.Orders[1].Items["bar"]: got <missing>, want {1 1}`,
+		`.Orders[1].Items["foo"]: got {1 1}, want <missing>`,
+		`.Tags: got [x], want <nil>`,
+		`.note: got "one", want "two"`,
+	}
+	if len(diff) != len(expectedDiff) {
+		t.Fatalf("wrong diff: %v", diff)
+	}
+	for i := range diff {
+		if diff[i] != expectedDiff[i] {
+			t.Errorf("wrong diff line %d: %q", i, diff[i])
+		}
+	}
+	if details[1].Diff() != nil {
+		t.Errorf("simple values must not have a diff: %v", details[1].Diff())
+	}
+
+	// Only visualization.
+	tassert := asserts.NewTesting(t, asserts.NoFailing)
+	tassert.Equal(obtained, expected, "test fails but passes, just visualization")
+}
+
+// TestGenericAsserts tests the type-safe generic assertions.
+func TestGenericAsserts(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	type id string
+	m := map[string]int{"one": 1, "two": 2}
+
+	asserts.EqualT(successfulAssert, int64(1), 1, "should not fail")
+	asserts.EqualT(successfulAssert, id("a"), "a", "should not fail")
+	asserts.EqualT(failingAssert, uint32(1), 2, "should fail and be logged")
+	asserts.DifferentT(successfulAssert, "a", "b", "should not fail")
+	asserts.DifferentT(failingAssert, 1.0, 1.0, "should fail and be logged")
+	asserts.ZeroT(successfulAssert, id(""), "should not fail")
+	asserts.ZeroT(failingAssert, int8(1), "should fail and be logged")
+	asserts.ContainsT(successfulAssert, 4711, []int{1, 4711, 2}, "should not fail")
+	asserts.ContainsT(failingAssert, "x", []string{"a", "b"}, "should fail and be logged")
+	asserts.NotContainsT(successfulAssert, id("x"), []id{"a", "b"}, "should not fail")
+	asserts.NotContainsT(failingAssert, 2, []int{1, 2, 3}, "should fail and be logged")
+	asserts.RangeT(successfulAssert, uint32(5), 1, 10, "should not fail")
+	asserts.RangeT(successfulAssert, float32(1.5), 1.0, 2.0, "should not fail")
+	asserts.RangeT(successfulAssert, "foo", "a", "z", "should not fail")
+	asserts.RangeT(failingAssert, int64(11), 1, 10, "should fail and be logged")
+	asserts.AboutT(successfulAssert, float32(0.95), 1.0, 0.1, "should not fail")
+	asserts.AboutT(failingAssert, float32(0.8), 1.0, 0.1, "should fail and be logged")
+	asserts.LengthT(successfulAssert, []id{"a", "b"}, 2, "should not fail")
+	asserts.LengthT(failingAssert, []int{}, 1, "should fail and be logged")
+	asserts.KeyT(successfulAssert, m, "one", "should not fail")
+	asserts.KeyT(failingAssert, m, "three", "should fail and be logged")
+
+	assert, failures := asserts.NewValidation()
+	asserts.EqualT(assert, 1, 2)
+	location, _ := failures.Details()[0].Location()
+	successfulAssert.Substring("asserts_test.go", location, "location has to point to caller")
+	successfulAssert.Equal(failures.Details()[0].Test(), asserts.Equal)
+}
+
+// TestAssertGroup tests the collecting of failures in groups.
+func TestAssertGroup(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	successfulAssert.Group("succeeding", func(g *asserts.Asserts) {
+		g.True(true)
+		g.Equal(1, 1)
+	}, "should not fail")
+	failingAssert.Group("failing", func(g *asserts.Asserts) {
+		g.True(false)
+		g.Equal(1, 2)
+	}, "should fail and be logged")
+
+	assert, failures := asserts.NewValidation()
+	ok := assert.Group("fields", func(g *asserts.Asserts) {
+		g.Equal("Alice", "Bob", "name")
+		g.True(true, "valid")
+		g.Equal(41, 42, "age")
+		g.Group("nested", func(ng *asserts.Asserts) {
+			ng.Nil("not nil", "nested")
+		})
+	})
+	successfulAssert.False(ok, "group has to fail")
+	successfulAssert.Length(failures.Errors(), 1, "only one failure for the group")
+	detail := failures.Details()[0]
+	successfulAssert.Equal(detail.Test(), asserts.Group)
+	location, fun := detail.Location()
+	successfulAssert.Substring("asserts_test.go", location)
+	successfulAssert.Equal(fun, "TestAssertGroup")
+	successfulAssert.Substring("'fields' has 3 failure(s)", detail.Error().Error())
+	successfulAssert.Substring("(name)", detail.Error().Error())
+	successfulAssert.Substring("(age)", detail.Error().Error())
+	successfulAssert.Substring("'nested' has 1 failure(s)", detail.Error().Error())
+
+	// Only visualization.
+	tassert := asserts.NewTesting(t, asserts.NoFailing)
+	tassert.Group("visualization", func(g *asserts.Asserts) {
+		g.Logf("logging inside a group")
+		g.Equal("Alice", "Bob", "name")
+		g.Equal(41, 42, "age")
+	}, "test fails but passes, just visualization")
+}
+
+// money helps testing the usage of the Equal() method.
+type money struct {
+	cents int
+	note  string
+}
+
+// Equal compares money only by its cents.
+func (m money) Equal(other money) bool {
+	return m.cents == other.cents
+}
+
+// TestAssertEqualWith tests the EqualWith() assertion.
+func TestAssertEqualWith(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	type record struct {
+		ID      int
+		Name    string
+		Score   float64
+		Created time.Time
+		Tags    []string
+		secret  string
+	}
+	type opaque struct {
+		n int
+	}
+	now := time.Now()
+	a := record{1, "a", 1.0, now, []string{"x", "y", "y"}, "foo"}
+	b := record{2, "a", 1.0001, now.UTC(), []string{"y", "x", "y"}, "bar"}
+
+	successfulAssert.EqualWith(a, b,
+		asserts.IgnoreFields("ID"),
+		asserts.IgnoreUnexported(),
+		asserts.FloatTolerance(0.001),
+		asserts.TimeEquality(),
+		asserts.UnorderedSlices())
+	successfulAssert.EqualWith(a, b,
+		asserts.IgnoreFields("record.ID", "secret", "Created"),
+		asserts.FloatTolerance(0.001),
+		asserts.UnorderedSlices())
+	successfulAssert.EqualWith(money{100, "a"}, money{100, "b"}, asserts.EqualMethod())
+	successfulAssert.EqualWith([]int{1, 2, 3}, []int{3, 1, 2}, asserts.UnorderedSlices())
+	successfulAssert.EqualWith(opaque{1}, opaque{1}, asserts.IgnoreUnexported())
+	failingAssert.EqualWith(opaque{1}, opaque{2}, asserts.IgnoreUnexported())
+	failingAssert.EqualWith(now, now.Add(time.Second), asserts.IgnoreUnexported())
+	successfulAssert.EqualWith(now, now.UTC(), asserts.IgnoreUnexported(), asserts.TimeEquality())
+	failingAssert.EqualWith(a, b, asserts.IgnoreFields("ID"))
+	failingAssert.EqualWith(money{100, "a"}, money{100, "b"})
+	failingAssert.EqualWith([]int{1, 2, 3}, []int{3, 1, 1}, asserts.UnorderedSlices())
+
+	assert, failures := asserts.NewValidation()
+	assert.EqualWith([]int{1, 2, 3}, []int{3, 1, 1}, asserts.UnorderedSlices(), asserts.Message("ones"))
+	assert.EqualWith([]record{a}, []record{b}, asserts.UnorderedSlices())
+	assert.EqualWith(opaque{1}, opaque{2}, asserts.IgnoreUnexported())
+	details := failures.Details()
+	successfulAssert.Length(details, 3)
+	successfulAssert.Equal(details[0].Test(), asserts.EqualWith)
+	successfulAssert.Equal(details[0].Diff(), []string{".: extra 2", ".: missing 1"})
+	successfulAssert.Substring("with options unordered slices", details[0].Error().Error())
+	successfulAssert.Equal(details[0].Message(), "ones")
+	successfulAssert.Length(details[1].Diff(), 2)
+	successfulAssert.Substring(".: extra {1 a 1 ", details[1].Diff()[0])
+	successfulAssert.Substring(".: missing {2 a 1.0001 ", details[1].Diff()[1])
+	successfulAssert.Equal(details[2].Diff(), []string{".: got {1}, want {2}"})
+
+	// Only visualization.
+	tassert := asserts.NewTesting(t, asserts.NoFailing)
+	tassert.EqualWith(a, b, asserts.IgnoreFields("ID"), asserts.TimeEquality())
+}
+
+// TestAssertGolden tests the Golden() assertion.
+func TestAssertGolden(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	successfulAssert.Golden("greeting", "Hello,\nWorld!\n", "should not fail")
+	successfulAssert.Golden("greeting", []byte("Hello,\nWorld!\n"), "should not fail")
+	failingAssert.Golden("greeting", "Hello,\nMoon!\n", "should fail and be logged")
+	failingAssert.Golden("does-not-exist", "Hello", "should fail and be logged")
+
+	assert, failures := asserts.NewValidation()
+	assert.Golden("greeting", "Hi,\nWorld!\nHow are you?\n")
+	detail := failures.Details()[0]
+	successfulAssert.Equal(detail.Test(), asserts.Golden)
+	successfulAssert.Equal(detail.Diff(), []string{"-1: Hello,", "+1: Hi,", "+3: How are you?"})
+	assert.Golden("does-not-exist", "Hello", "missing")
+	successfulAssert.Match(failures.Details()[1].Message(), "missing cannot read golden file: .*")
+
+	// Update mode.
+	t.Setenv(asserts.UpdateEnv, "true")
+	filename := filepath.Join("testdata", "updated.golden")
+	defer os.Remove(filename)
+	successfulAssert.Golden("updated", "new content", "should write")
+	data, err := os.ReadFile(filename)
+	successfulAssert.NoError(err)
+	successfulAssert.Equal(string(data), "new content")
+}
+
+// TestAssertJSON tests the JSONEqual() and JSONPath() assertions.
+func TestAssertJSON(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	doc := `{"name": "order", "items": [{"id": 1, "sku": "a"}, {"id": 2, "sku": "b"}], "a key": true}`
+
+	successfulAssert.JSONEqual(doc, []byte(`{
+		"a key": true,
+		"items": [{"sku": "a", "id": 1.0}, {"sku": "b", "id": 2}],
+		"name": "order"
+	}`), "should not fail")
+	failingAssert.JSONEqual(doc, `{"name": "order"}`, "should fail and be logged")
+	failingAssert.JSONEqual(doc, `{invalid`, "should fail and be logged")
+	failingAssert.JSONEqual(4711, doc, "should fail and be logged")
+
+	successfulAssert.JSONPath(doc, "$.name", "order", "should not fail")
+	successfulAssert.JSONPath(doc, "$.items[1].id", 2, "should not fail")
+	successfulAssert.JSONPath(doc, `$["a key"]`, true, "should not fail")
+	successfulAssert.JSONPath(doc, "$.items[0]", map[string]any{"id": 1, "sku": "a"}, "should not fail")
+	failingAssert.JSONPath(doc, "$.items[1].sku", "a", "should fail and be logged")
+	failingAssert.JSONPath(doc, "$.items[5].sku", "a", "should fail and be logged")
+	failingAssert.JSONPath(doc, "items", "a", "should fail and be logged")
+
+	successfulAssert.JSONEqual(`{"n": 1e2}`, `{"n": 100}`, "should not fail")
+	successfulAssert.JSONPath(`{"n": 9007199254740993}`, "$.n", int64(9007199254740993), "should not fail")
+	failingAssert.JSONEqual(`{"n": 9007199254740993}`, `{"n": 9007199254740992}`, "should fail and be logged")
+	failingAssert.JSONPath(`{"n": 9007199254740993}`, "$.n", int64(9007199254740992), "should fail and be logged")
+	failingAssert.JSONEqual(`{"n": 1} {}`, `{"n": 1}`, "should fail and be logged")
+
+	assert, failures := asserts.NewValidation()
+	assert.JSONEqual(doc, `{"name": "other", "items": [{"id": 1, "sku": "a"}], "a key": true, "x": null}`)
+	assert.JSONPath(doc, "$.items[0]", map[string]any{"id": 2, "sku": "a"})
+	details := failures.Details()
+	successfulAssert.Equal(details[0].Test(), asserts.JSONEqual)
+	successfulAssert.Equal(details[0].Diff(), []string{
+		`$.items[1]: got {"id":2,"sku":"b"}, want <missing>`,
+		`$.name: got "order", want "other"`,
+		`$.x: got <missing>, want null`,
+	})
+	successfulAssert.Equal(details[1].Test(), asserts.JSONPath)
+	successfulAssert.Equal(details[1].Diff(), []string{`$.items[0].id: got 1, want 2`})
+
+	assert.JSONEqual(`{invalid`, doc, "parsing")
+	assert.JSONPath(doc, "$.items[5]", 1, "lookup")
+	assert.JSONPath(`{"n": 9007199254740993}`, "$.n", int64(9007199254740992))
+	details = failures.Details()
+	successfulAssert.Length(details, 5)
+	successfulAssert.Equal(details[2].Obtained(), `{invalid`)
+	successfulAssert.Match(details[2].Message(), "parsing invalid obtained JSON: .*")
+	successfulAssert.Equal(details[3].Obtained(), doc)
+	successfulAssert.Equal(details[3].Message(), `lookup path "$.items[5]" not found: no array element 5`)
+	successfulAssert.Equal(details[4].Diff(), []string{`$.n: got 9007199254740993, want 9007199254740992`})
+}
+
+// TestAssertEventually tests the Eventually() and Consistently() assertions.
+func TestAssertEventually(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	i := 0
+	successfulAssert.Eventually(func() error {
+		i++
+		if i < 5 {
+			return errors.New("not yet")
+		}
+		return nil
+	}, time.Second, 10*time.Millisecond, "should succeed")
+	failingAssert.Eventually(func() error {
+		return errors.New("never")
+	}, 50*time.Millisecond, 10*time.Millisecond, "should fail")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	failingAssert.EventuallyCtx(ctx, func() error {
+		return errors.New("never")
+	}, time.Second, 10*time.Millisecond, "should be cancelled")
+
+	successfulAssert.Consistently(func() error {
+		return nil
+	}, 50*time.Millisecond, 10*time.Millisecond, "should succeed")
+	j := 0
+	failingAssert.Consistently(func() error {
+		j++
+		if j > 2 {
+			return errors.New("broken")
+		}
+		return nil
+	}, time.Second, 10*time.Millisecond, "should fail")
+
+	assert, failures := asserts.NewValidation()
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ConsistentlyCtx(ctx, func() error { return nil }, time.Second, 10*time.Millisecond)
+	assert.Eventually(func() error { return errors.New("ouch") }, 50*time.Millisecond, 10*time.Millisecond)
+	details := failures.Details()
+	successfulAssert.Length(details, 2)
+	successfulAssert.Equal(details[0].Test(), asserts.Consistently)
+	successfulAssert.Substring("context deadline exceeded after", details[0].Error().Error())
+	successfulAssert.Equal(details[1].Test(), asserts.Eventually)
+	successfulAssert.Match(details[1].Error().Error(), ".*timeout after .* and [0-9]+ attempt.*, last error: ouch.*")
+}
+
+// TestAssertErrorIsAs tests the ErrorIs() and ErrorAs() assertions.
+func TestAssertErrorIsAs(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	errBase := errors.New("base")
+	errOther := errors.New("other")
+	errWrapped := fmt.Errorf("wrapped: %w", errBase)
+	errJoined := errors.Join(errOther, errWrapped)
+	errPath := fmt.Errorf("cannot open: %w", &os.PathError{Op: "open", Path: "/foo", Err: errBase})
+
+	successfulAssert.ErrorIs(errWrapped, errBase, "should not fail")
+	successfulAssert.ErrorIs(errJoined, errBase, "should not fail")
+	successfulAssert.ErrorIs(withErr{errWrapped}, errBase, "should not fail")
+	failingAssert.ErrorIs(errWrapped, errOther, "should fail and be logged")
+	failingAssert.ErrorIs(nil, errOther, "should fail and be logged")
+
+	var pathErr *os.PathError
+	successfulAssert.ErrorAs(errPath, &pathErr, "should not fail")
+	successfulAssert.Equal(pathErr.Path, "/foo")
+	failingAssert.ErrorAs(errJoined, &pathErr, "should fail and be logged")
+	failingAssert.ErrorAs(errPath, pathErr, "should fail and be logged")
+	failingAssert.ErrorAs(nil, &pathErr, "should fail and be logged")
+
+	successfulAssert.Equal(asserts.ErrorChain(errJoined), "*errors.joinError: other\\nwrapped: base\n"+
+		"  *errors.errorString: other\n"+
+		"  *fmt.wrapError: wrapped: base\n"+
+		"  *errors.errorString: base")
+
+	assert, failures := asserts.NewValidation()
+	assert.ErrorIs(errWrapped, errOther)
+	detail := failures.Details()[0]
+	successfulAssert.Equal(detail.Test(), asserts.ErrorIs)
+	successfulAssert.Equal(detail.Diff(), []string{"*fmt.wrapError: wrapped: base", "*errors.errorString: base"})
+	assert.ErrorAs(errPath, pathErr, "target")
+	assert.ErrorIs(nil, errOther, "nil")
+	details := failures.Details()
+	successfulAssert.Length(details, 3)
+	successfulAssert.Equal(details[1].Message(), "target target must be a pointer to an interface or to a type implementing error")
+	successfulAssert.Equal(details[2].Message(), "nil error is nil")
+
+	// Only visualization.
+	tassert := asserts.NewTesting(t, asserts.NoFailing)
+	tassert.ErrorIs(errJoined, errOther, "passes")
+	tassert.ErrorAs(errJoined, &pathErr, "test fails but passes, just visualization")
+}
+
+// TestReports tests writing failures as JSON lines and JUnit XML.
+func TestReports(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	assert, failures := asserts.NewValidation()
+
+	assert.True(false, "first")
+	assert.Equal([]int{1, 2}, []int{1, 3}, "second")
+
+	// JSON lines.
+	var jbuf bytes.Buffer
+	err := asserts.WriteJSON(&jbuf, failures)
+	successfulAssert.NoError(err)
+	lines := strings.Split(strings.TrimSpace(jbuf.String()), "\n")
+	successfulAssert.Length(lines, 2)
+	var record map[string]any
+	err = json.Unmarshal([]byte(lines[1]), &record)
+	successfulAssert.NoError(err)
+	successfulAssert.Equal(record["test"], "equal")
+	successfulAssert.Equal(record["function"], "TestReports")
+	successfulAssert.Equal(record["message"], "second")
+	successfulAssert.Equal(record["obtained"], "[1 2]")
+	successfulAssert.Equal(record["expected"], "[1 3]")
+	successfulAssert.Equal(record["diff"], []any{"[1]: got 2, want 3"})
+
+	// JUnit XML.
+	var xbuf bytes.Buffer
+	err = asserts.WriteJUnit(&xbuf, "validation", failures)
+	successfulAssert.NoError(err)
+	var suite struct {
+		Name     string `xml:"name,attr"`
+		Failures int    `xml:"failures,attr"`
+		Cases    []struct {
+			Name    string `xml:"name,attr"`
+			Failure struct {
+				Type    string `xml:"type,attr"`
+				Content string `xml:",chardata"`
+			} `xml:"failure"`
+		} `xml:"testcase"`
+	}
+	err = xml.Unmarshal(xbuf.Bytes(), &suite)
+	successfulAssert.NoError(err)
+	successfulAssert.Equal(suite.Name, "validation")
+	successfulAssert.Equal(suite.Failures, 2)
+	successfulAssert.Length(suite.Cases, 2)
+	successfulAssert.Equal(suite.Cases[0].Failure.Type, "true")
+	successfulAssert.Substring("TestReports asserts_test.go:", suite.Cases[0].Name)
+	successfulAssert.Substring("[1]: got 2, want 3", suite.Cases[1].Failure.Content)
+}
+
+// TestFailureDetailValues tests the retaining of obtained and
+// expected values in the failure details.
+func TestFailureDetailValues(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	assert, failures := asserts.NewValidation()
+
+	type person struct {
+		ID   int
+		Name string
+	}
+	obtained := person{1, "foo"}
+	expected := person{1, "bar"}
+
+	assert.Equal(obtained, expected)
+	assert.Range(11, 1, 10)
+	assert.EqualWith(obtained, expected, asserts.IgnoreFields("ID"))
+	assert.JSONPath(`{"a": 1}`, "$.a", 2)
+
+	details := failures.Details()
+	successfulAssert.Length(details, 4)
+	successfulAssert.Equal(details[0].Obtained(), obtained)
+	successfulAssert.Equal(details[0].Expected(), expected)
+	successfulAssert.Equal(details[0].Diff(), []string{`.Name: got "foo", want "bar"`})
+	successfulAssert.Equal(details[1].Obtained(), 11)
+	successfulAssert.Equal(details[1].Expected(), []any{1, 10})
+	successfulAssert.Equal(details[2].Expected(), expected)
+	successfulAssert.Equal(details[3].Obtained(), json.Number("1"))
+	successfulAssert.Equal(details[3].Expected(), json.Number("2"))
+}
+
+// TestAssertSnapshot tests the MatchSnapshot() and CheckSnapshots() assertions.
+func TestAssertSnapshot(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	type item struct {
+		SKU string
+		Qty int
+	}
+	type order struct {
+		ID      string
+		Created time.Time
+		Items   []*item
+		Meta    map[string]any
+	}
+	o := order{
+		ID:      "4711",
+		Created: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+		Items:   []*item{{"a", 2}, {"b", 1}},
+		Meta:    map[string]any{"z": true, "a": 1.5},
+	}
+
+	successfulAssert.MatchSnapshot(o, "should not fail")
+	successfulAssert.MatchSnapshot(map[int]string{3: "c", 1: "a", 2: "b"}, "should not fail")
+	successfulAssert.CheckSnapshots("should not fail")
+
+	assert, failures := asserts.NewValidation()
+	o.Items[1].Qty = 5
+	assert.MatchSnapshot(o)
+	assert.CheckSnapshots()
+	details := failures.Details()
+	successfulAssert.Length(details, 2)
+	successfulAssert.Equal(details[0].Test(), asserts.Snapshot)
+	successfulAssert.Equal(details[0].Diff(), []string{"-11: \t\t\tQty: 1,", "+11: \t\t\tQty: 5,"})
+	successfulAssert.Equal(details[1].Obtained(), []string{"TestAssertSnapshot 2"})
+	failingAssert.MatchSnapshot(o, "should fail and be logged")
+
+	// Update mode in a temporary directory.
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "TestAssertSnapshot.snap")
+	t.Setenv(asserts.UpdateEnv, "true")
+
+	assert, failures = asserts.NewValidation()
+	assert.SetSnapshotDir(dir)
+	assert.MatchSnapshot("one")
+	matchSnapshot(assert, "two\n--- TestAssertSnapshot 1 (lines: 1) ---\nthree")
+	successfulAssert.False(failures.HasErrors())
+	data, err := os.ReadFile(filename)
+	successfulAssert.NoError(err)
+	successfulAssert.Equal(string(data), "--- TestAssertSnapshot 1 (lines: 1) ---\n\"one\"\n"+
+		"--- TestAssertSnapshot 2 (lines: 1) ---\n\"two\\n--- TestAssertSnapshot 1 (lines: 1) ---\\nthree\"\n")
+
+	assert, failures = asserts.NewValidation()
+	assert.SetSnapshotDir(dir)
+	assert.MatchSnapshot("uno")
+	assert.CheckSnapshots()
+	successfulAssert.False(failures.HasErrors())
+	data, err = os.ReadFile(filename)
+	successfulAssert.NoError(err)
+	successfulAssert.Equal(string(data), "--- TestAssertSnapshot 1 (lines: 1) ---\n\"uno\"\n")
+
+	// Sub-tests keyed by their names, obsolete snapshots pruned at their end.
+	successfulAssert.NoError(os.WriteFile(filename, []byte(
+		"--- TestAssertSnapshot/prune 1 (lines: 1) ---\n\"a\"\n--- TestAssertSnapshot/prune 2 (lines: 1) ---\n\"b\"\n"), 0644))
+	t.Run("prune", func(t *testing.T) {
+		assert := asserts.NewTesting(t, asserts.FailStop)
+		assert.SetSnapshotDir(dir)
+		assert.MatchSnapshot("a\n--- TestAssertSnapshot/prune 2 (lines: 1) ---")
+	})
+	data, err = os.ReadFile(filename)
+	successfulAssert.NoError(err)
+	successfulAssert.Equal(string(data), "--- TestAssertSnapshot/prune 1 (lines: 1) ---\n\"a\\n--- TestAssertSnapshot/prune 2 (lines: 1) ---\"\n")
+
+	// Obsolete snapshots of sub-tests reported at their end.
+	t.Setenv(asserts.UpdateEnv, "false")
+	successfulAssert.NoError(os.WriteFile(filename, []byte(
+		"--- TestAssertSnapshot/report 1 (lines: 1) ---\n\"a\"\n--- TestAssertSnapshot/report 2 (lines: 1) ---\n\"b\"\n"), 0644))
+	bp := asserts.NewBufferedPrinter()
+	t.Run("report", func(t *testing.T) {
+		assert := asserts.NewTesting(t, asserts.NoFailing)
+		assert.SetPrinter(bp)
+		assert.SetSnapshotDir(dir)
+		assert.MatchSnapshot("a")
+		successfulAssert.Length(bp.Entries(), 0)
+	})
+	entries, err := bp.Grep(`assert 'snapshot' .* failed \{got: \[TestAssertSnapshot/report 2\], info: obsolete snapshots of TestAssertSnapshot/report\}`)
+	successfulAssert.NoError(err)
+	successfulAssert.Length(entries, 1)
+}
+
+// TestTerminalPrinter tests the rendering of failures for terminals.
+func TestTerminalPrinter(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	t.Setenv(asserts.PrinterEnv, "plain")
+
+	bp := asserts.NewBufferedPrinter()
+	assert := asserts.NewTesting(t, asserts.NoFailing)
+	assert.SetPrinter(asserts.NewTerminalPrinter(bp))
+	assert.Equal("one\ntwo\nthree", "one\n2\nthree")
+	b := bp.Flush()
+	successfulAssert.Length(b, 1)
+	successfulAssert.Contains("assert 'equal' in TestTerminalPrinter() failed", b[0])
+	successfulAssert.Contains("\t@@ -1,3 +1,3 @@\n\t one\n\t-2\n\t+two\n\t three\n", b[0])
+	successfulAssert.Contains("\t  got   | want\n", b[0])
+	successfulAssert.Contains("\t! two   | 2\n", b[0])
+	successfulAssert.Contains(`|     assert.Equal("one\ntwo\nthree", "one\n2\nthree")`, b[0])
+	successfulAssert.False(strings.Contains(b[0], "\x1b["))
+
+	// Forced colors.
+	t.Setenv(asserts.PrinterEnv, "color")
+	assert.SetPrinter(asserts.NewTerminalPrinter(bp))
+	assert.Equal(1, 2)
+	b = bp.Flush()
+	successfulAssert.Length(b, 1)
+	successfulAssert.Contains("\x1b[31m1 (int)\x1b[0m", b[0])
+	successfulAssert.Contains("\x1b[32m2 (int)\x1b[0m", b[0])
+	assert.Equal("one\ntwo", "one\n2")
+	b = bp.Flush()
+	successfulAssert.Length(b, 1)
+	successfulAssert.Contains("\t\x1b[36m@@ -1,2 +1,2 @@\x1b[0m\n", b[0])
+	successfulAssert.Contains("\t\x1b[31m-2\x1b[0m\n\t\x1b[32m+two\x1b[0m\n", b[0])
+
+	// Selection by environment.
+	assert = asserts.NewTesting(t, asserts.NoFailing)
+	_, isT := assert.SetPrinter(bp).(*testing.T)
+	successfulAssert.False(isT)
+	t.Setenv(asserts.PrinterEnv, "plain")
+	assert = asserts.NewTesting(t, asserts.NoFailing)
+	_, isT = assert.SetPrinter(bp).(*testing.T)
+	successfulAssert.True(isT)
+}
+
+// TestBufferedPrinter tests the querying of the buffered printer.
+func TestBufferedPrinter(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	bp := asserts.NewBufferedPrinter()
+
+	// Concurrent prints by asserts in goroutines.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert := asserts.NewTesting(t, asserts.NoFailing)
+			assert.SetPrinter(bp)
+			assert.Logf("goroutine %d", i)
+		}(i)
+	}
+	wg.Wait()
+	bp.Errorf("error %d", 1)
+
+	successfulAssert.Length(bp.Entries(), 11)
+	successfulAssert.Length(bp.Logs(), 10)
+	errs := bp.Errors()
+	successfulAssert.Length(errs, 1)
+	successfulAssert.Equal(errs[0].Level, asserts.ErrorLevel)
+	successfulAssert.Equal(errs[0].Text, "error 1")
+	successfulAssert.False(errs[0].Timestamp.IsZero())
+	entries, err := bp.Grep(`goroutine [0-4]\n$`)
+	successfulAssert.NoError(err)
+	successfulAssert.Length(entries, 5)
+	_, err = bp.Grep("(")
+	successfulAssert.ErrorContains(err, "missing closing )")
+
+	// Waiting for a print in the background.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		assert := asserts.NewTesting(t, asserts.NoFailing)
+		assert.SetPrinter(bp)
+		assert.Equal(1, 2)
+	}()
+	entry, err := bp.WaitFor(`assert 'equal' .* failed`, time.Second)
+	successfulAssert.NoError(err)
+	successfulAssert.Equal(entry.Level, asserts.LogLevel)
+	_, err = bp.WaitFor("never printed", 10*time.Millisecond)
+	successfulAssert.ErrorContains(err, "no entry matching")
+
+	b := bp.Flush()
+	successfulAssert.Length(b, 12)
+	successfulAssert.Equal(b[10], "[ERR] error 1")
+	successfulAssert.Length(bp.Entries(), 0)
+}
+
+// TestRegisterTest tests own assertions with registered tests.
+func TestRegisterTest(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	assertEven(successfulAssert, 4, "should not fail")
+	assertEven(failingAssert, 5, "should fail and be logged")
+
+	successfulAssert.Equal(asserts.RegisterTest("even"), evenTest)
+	successfulAssert.Equal(asserts.RegisterTest("equal"), asserts.Equal)
+	successfulAssert.Equal(evenTest.String(), "even")
+	successfulAssert.Equal(asserts.Test(0).String(), "invalid")
+
+	assert, failures := asserts.NewValidation()
+	successfulAssert.True(assertEven(assert, 2))
+	successfulAssert.False(assertEven(assert, 3, "three"))
+	evenLocation := callerLocation(-1)
+	details := failures.Details()
+	successfulAssert.Length(details, 1)
+	location, fun := details[0].Location()
+	successfulAssert.Equal(location, evenLocation)
+	successfulAssert.Equal(fun, "TestRegisterTest")
+	successfulAssert.Equal(details[0].Test(), evenTest)
+	successfulAssert.Equal(details[0].Error().Error(), "assert 'even' failed: 3 is odd (three)")
+
+	bp := asserts.NewBufferedPrinter()
+	tassert := asserts.NewTesting(t, asserts.NoFailing)
+	tassert.SetPrinter(bp)
+	assertEven(tassert, 5)
+	printLocation := callerLocation(-1)
+	b := bp.Flush()
+	successfulAssert.Length(b, 1)
+	successfulAssert.Equal(b[0], "[LOG] "+printLocation+" assert 'even' in TestRegisterTest() failed {5 is odd}\n")
+}
+
+// TestFluent tests the fluent assertions.
+func TestFluent(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+	errNotFound := errors.New("not found")
+	type flag bool
+
+	successfulAssert.That("Alice").IsNotEmpty().HasLength(5).Matches("^[A-Z]")
+	successfulAssert.That(true).IsTrue().IsNotNil().Equals(true).IsDifferent(false)
+	successfulAssert.That(flag(true)).IsTrue()
+	successfulAssert.That(flag(false)).IsFalse()
+	successfulAssert.That(fmt.Errorf("loading: %w", errNotFound)).Is(errNotFound)
+	successfulAssert.That(nil).IsNil().IsZero().IsNoError()
+	successfulAssert.ThatSlice([]int{1, 2, 3}).IsNotEmpty().ContainsAll(1, 2).IsSorted()
+	failingAssert.That("bob").Matches("^[A-Z]")
+	failingAssert.That("true").IsFalse()
+	failingAssert.ThatSlice([]int{1, 3, 2}).IsSorted()
+
+	// Short-circuit after the first failure.
+	assert, failures := asserts.NewValidation()
+	successfulAssert.True(assert.That("Alice").IsNotEmpty().Passed())
+	successfulAssert.False(assert.That("bob").IsNotEmpty().Matches("^[A-Z]").HasLength(5).Passed())
+	successfulAssert.False(assert.ThatSlice([]int{1, 3, 2}).ContainsAll(1, 4, 5).IsSorted().Passed())
+	successfulAssert.False(assert.ThatSlice([]string{"a", "c", "b"}).IsSorted().Passed())
+	successfulAssert.False(assert.That("true").IsFalse().Passed())
+	locations := []string{callerLocation(-4), callerLocation(-3), callerLocation(-2), callerLocation(-1)}
+	details := failures.Details()
+	successfulAssert.Length(details, 4)
+	successfulAssert.Equal(details[0].Test(), asserts.ContainsMatch)
+	successfulAssert.Equal(details[0].Error().Error(), "assert 'contains match' failed: 'bob' <> '^[A-Z]'")
+	successfulAssert.Equal(details[1].Test(), asserts.ContainsAll)
+	successfulAssert.Equal(details[1].Diff(), []string{"missing: 4", "missing: 5"})
+	successfulAssert.Equal(details[2].Test(), asserts.Sorted)
+	successfulAssert.Equal(details[2].Error().Error(), "assert 'sorted' failed: '[a c b]' is not sorted: [2]: b after [1]: c")
+	successfulAssert.Equal(details[3].Test(), asserts.False)
+	successfulAssert.Equal(details[3].Obtained(), "true")
+	successfulAssert.Equal(details[3].Error().Error(), "assert 'false' failed: 'true' (type missmatch: obtained string is no bool)")
+	for i, expected := range locations {
+		location, fun := details[i].Location()
+		successfulAssert.Equal(location, expected)
+		successfulAssert.Equal(fun, "TestFluent")
+	}
+}
+
+// TestCollectionAsserts tests the assertions on arrays and slices.
+func TestCollectionAsserts(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	successfulAssert.ContainsAll([]int{1, 2, 3, 4}, []int{4, 2})
+	successfulAssert.ContainsAll([]any{1, "2"}, [1]any{"2"})
+	failingAssert.ContainsAll([]int{1, 2, 3}, []int{4, 2})
+	failingAssert.ContainsAll("123", []int{1})
+	successfulAssert.ContainsAny([]string{"a", "b"}, []string{"x", "b"})
+	failingAssert.ContainsAny([]string{"a", "b"}, []string{"x", "y"})
+	failingAssert.ContainsAny([]string{"a", "b"}, []string{})
+	successfulAssert.ElementsMatch([]int{3, 1, 2, 1}, []int{1, 1, 2, 3})
+	failingAssert.ElementsMatch([]int{3, 1, 2, 2}, []int{1, 1, 2, 3})
+	successfulAssert.Subset([]int{3, 1}, []int{1, 2, 3})
+	failingAssert.Subset([]int{3, 4}, []int{1, 2, 3})
+	successfulAssert.Sorted([]int{1, 2, 2, 3})
+	successfulAssert.Sorted([]string{})
+	failingAssert.Sorted([]float64{1.0, 0.5})
+	failingAssert.Sorted([]bool{true, false})
+	successfulAssert.Unique([]string{"a", "b", "c"})
+	failingAssert.Unique([]string{"a", "b", "a"})
+
+	type person struct {
+		Name string
+		Age  int
+	}
+	people := []person{{"Bob", 30}, {"Alice", 40}, {"Carol", 35}}
+	successfulAssert.True(asserts.SortedByT(successfulAssert, people[:2], func(p person) int { return p.Age }))
+	failingAssert.False(asserts.SortedByT(failingAssert, people, func(p person) int { return p.Age }))
+
+	// Failure details.
+	assert, failures := asserts.NewValidation()
+	assert.ElementsMatch([]int{3, 1, 2, 2}, []int{1, 1, 2, 3})
+	assert.ContainsAll([]int{1, 2, 3}, []int{4, 2})
+	assert.Subset([]int{3, 4}, []int{1, 2, 3})
+	assert.Unique([]string{"a", "b", "a"})
+	asserts.SortedByT(assert, people, func(p person) string { return p.Name })
+	assert.ContainsAny([]int{1}, []int{2, 3})
+	assert.ContainsAll("123", []int{1}, "elements")
+	details := failures.Details()
+	successfulAssert.Length(details, 7)
+	successfulAssert.Equal(details[0].Diff(), []string{"missing: 1", "extra: 2"})
+	successfulAssert.Equal(details[1].Diff(), []string{"missing: 4"})
+	successfulAssert.Equal(details[2].Diff(), []string{"extra: 4"})
+	successfulAssert.Equal(details[3].Diff(), []string{"duplicate: [2] a equals [0]"})
+	successfulAssert.Equal(details[3].Error().Error(), "assert 'unique' failed: '[a b a]' has duplicates: duplicate: [2] a equals [0]")
+	successfulAssert.Equal(details[4].Diff(), []string{"[1]: {Alice 40} after [0]: {Bob 30}"})
+	successfulAssert.Equal(details[5].Error().Error(), "assert 'contains any' failed: '[1]' contains none of '[2 3]'")
+	successfulAssert.Match(details[6].Message(), "elements type missmatch: .*")
+}
+
+// TestMapAsserts tests the assertions on maps.
+func TestMapAsserts(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	successfulAssert.HasKey(m, "b")
+	failingAssert.HasKey(m, "d")
+	failingAssert.HasKey(m, 1)
+	failingAssert.HasKey([]int{1}, 0)
+	successfulAssert.HasValue(m, 3)
+	failingAssert.HasValue(m, 4)
+	successfulAssert.KeysEqual(m, []string{"c", "b", "a"})
+	successfulAssert.KeysEqual(m, map[string]bool{"a": true, "b": true, "c": false})
+	failingAssert.KeysEqual(m, []string{"a", "b"})
+	failingAssert.KeysEqual(m, []int{1})
+	successfulAssert.MapSubset(m, map[string]int{"a": 1, "c": 3})
+	successfulAssert.MapSubset(m, map[string]int{})
+	failingAssert.MapSubset(m, map[string]int{"a": 2})
+	failingAssert.MapSubset(m, map[int]int{1: 1})
+
+	// Failure details.
+	type user struct {
+		Name string
+		Tags []string
+	}
+	users := map[string]user{
+		"alice": {"Alice", []string{"admin"}},
+		"bob":   {"Bob", nil},
+	}
+	assert, failures := asserts.NewValidation()
+	assert.KeysEqual(m, []string{"a", "b", "d", "e"})
+	assert.MapSubset(users, map[string]user{
+		"alice": {"Alice", []string{"user"}},
+		"carol": {"Carol", nil},
+	})
+	assert.HasKey(m, "x")
+	assert.MapSubset(m, map[int]int{1: 1}, "subset")
+	details := failures.Details()
+	successfulAssert.Length(details, 4)
+	successfulAssert.Equal(details[0].Test(), asserts.KeysEqual)
+	successfulAssert.Equal(details[0].Diff(), []string{
+		`missing key: "d"`,
+		`missing key: "e"`,
+		`unexpected key: "c"`,
+	})
+	successfulAssert.Equal(details[1].Test(), asserts.MapSubset)
+	successfulAssert.Equal(details[1].Diff(), []string{
+		`["alice"].Tags[0]: got "admin", want "user"`,
+		`["carol"]: got <missing>, want {Carol []}`,
+	})
+	successfulAssert.Equal(details[2].Error().Error(), "assert 'has key' failed: 'map[a:1 b:2 c:3]' has no key 'x'")
+	successfulAssert.Match(details[3].Message(), "subset type missmatch: .*")
+}
+
+// TestTimeAsserts tests the assertions on times.
+func TestTimeAsserts(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	successfulAssert.NoError(err)
+	now := time.Now()
+	utc := time.Date(2023, 5, 1, 22, 30, 15, 0, time.UTC)
+
+	successfulAssert.TimeEqual(now, now.Round(0))
+	successfulAssert.TimeEqual(utc, utc.In(berlin))
+	failingAssert.TimeEqual(utc, utc.Add(time.Nanosecond))
+	successfulAssert.WithinDuration(utc, utc.Add(-time.Second), time.Second)
+	successfulAssert.WithinDuration(utc, utc.Add(time.Second), -time.Second)
+	failingAssert.WithinDuration(utc, utc.Add(time.Minute), time.Second)
+	successfulAssert.Before(utc, utc.Add(time.Nanosecond))
+	failingAssert.Before(utc, utc)
+	successfulAssert.After(utc.Add(time.Nanosecond), utc)
+	failingAssert.After(utc, utc)
+	successfulAssert.SameSecond(utc, utc.Add(500*time.Millisecond), nil)
+	failingAssert.SameSecond(utc, utc.Add(time.Second), nil)
+	successfulAssert.SameMinute(utc, utc.Add(30*time.Second), berlin)
+	failingAssert.SameMinute(utc, utc.Add(45*time.Second), berlin)
+	successfulAssert.SameDay(utc, utc.Add(time.Hour), nil)
+	successfulAssert.SameDay(utc, utc.Add(2*time.Hour), berlin)
+	failingAssert.SameDay(utc, utc.Add(2*time.Hour), nil)
+
+	// Failure details.
+	assert, failures := asserts.NewValidation()
+	assert.WithinDuration(utc, utc.Add(time.Minute), time.Second)
+	assert.SameDay(utc, utc.Add(-time.Hour), berlin)
+	assert.Before(utc, utc)
+	details := failures.Details()
+	successfulAssert.Length(details, 3)
+	successfulAssert.Equal(details[0].Error().Error(),
+		"assert 'within duration' failed: '2023-05-01T22:30:15Z' is not within '2023-05-01T22:31:15Z ± 1s'")
+	successfulAssert.Equal(details[0].Expected(), utc.Add(time.Minute))
+	successfulAssert.Equal(details[1].Error().Error(),
+		"assert 'same day' failed: '2023-05-01T22:30:15Z' is not in the same day as '2023-05-01T23:30:15+02:00 in Europe/Berlin'")
+	successfulAssert.Equal(details[2].Error().Error(),
+		"assert 'before' failed: '2023-05-01T22:30:15Z' is not before '2023-05-01T22:30:15Z'")
+}
+
+// TestNumericAsserts tests the assertions on numbers of all kinds.
+func TestNumericAsserts(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+	nan := math.NaN()
+	inf := math.Inf(1)
+
+	successfulAssert.Range(int64(5), 1, 10)
+	successfulAssert.Range(uint32(5), int8(1), uint64(10))
+	successfulAssert.Range(float32(2.5), 1, 2.5)
+	successfulAssert.Range(uint64(math.MaxUint64), uint64(math.MaxUint64-1), uint64(math.MaxUint64))
+	successfulAssert.Range(int64(-1), -1, uint(1))
+	failingAssert.Range(int16(0), 1, 10)
+	failingAssert.Range(uint64(math.MaxUint64), 0, int64(math.MaxInt64))
+	failingAssert.Range(nan, 0.0, 1.0)
+	failingAssert.Range(int64(5), "1", "10")
+	successfulAssert.Range(int64(1<<53+1), float64(1<<53), float64(1<<53+2))
+	successfulAssert.Range(uint64(math.MaxUint64), 1.5, math.Inf(1))
+	failingAssert.Range(int64(1<<53+1), float64(1<<53), float64(1<<53))
+	failingAssert.Range(uint64(1<<63+1), 0.0, float64(1<<63))
+	failingAssert.Range(-2, -1.5, 0.0)
+	successfulAssert.Range(-1, -1.5, 0.0)
+
+	successfulAssert.AboutRel(101.0, 100.0, 0.01)
+	successfulAssert.AboutRel(-99.0, -100.0, 0.01)
+	successfulAssert.AboutRel(0.0, 0.0, 0.0)
+	failingAssert.AboutRel(102.0, 100.0, 0.01)
+	failingAssert.AboutRel(nan, nan, 1.0)
+	successfulAssert.AboutRel(int64(99), uint8(100), 0.01)
+	successfulAssert.AboutRel(float32(1.5), 1, 0.5)
+	successfulAssert.AboutRel(inf, inf, 0.01)
+	failingAssert.AboutRel(inf, 1.0, 0.01)
+	failingAssert.AboutRel(int64(1<<62+1), int64(1<<62), 0)
+	failingAssert.AboutRel("1", 1, 0.01)
+	successfulAssert.AboutULP(0.1+0.2, 0.3, 1)
+	successfulAssert.AboutULP(float32(1.0), math.Nextafter32(1.0, 2.0), 1)
+	successfulAssert.AboutULP(0.0, math.Copysign(0, -1), 0)
+	successfulAssert.AboutULP(-math.SmallestNonzeroFloat64, math.SmallestNonzeroFloat64, 2)
+	failingAssert.AboutULP(1.0, 1.0000001, 100)
+	failingAssert.AboutULP(float32(1.0), 1.0, 100)
+	failingAssert.AboutULP(nan, nan, math.MaxUint64-1)
+
+	successfulAssert.Positive(uint8(1))
+	successfulAssert.Positive(inf)
+	failingAssert.Positive(int64(0))
+	failingAssert.Positive(nan)
+	failingAssert.Positive("1")
+	successfulAssert.Negative(int32(-1))
+	successfulAssert.Negative(float32(-0.5))
+	failingAssert.Negative(uint(0))
+	successfulAssert.NaN(nan)
+	successfulAssert.NaN(float32(nan))
+	failingAssert.NaN(1.0)
+	failingAssert.NaN(1)
+	successfulAssert.Inf(inf, 0)
+	successfulAssert.Inf(inf, 1)
+	successfulAssert.Inf(-inf, -1)
+	failingAssert.Inf(inf, -1)
+	failingAssert.Inf(math.MaxFloat64, 0)
+
+	successfulAssert.Increasing([]int{1, 2, 2, 3}, false)
+	successfulAssert.Increasing([]uint{1, 2, 3}, true)
+	successfulAssert.Increasing([]float64{}, true)
+	failingAssert.Increasing([]int{1, 2, 2, 3}, true)
+	failingAssert.Increasing([]float64{1, nan}, false)
+	failingAssert.Increasing([]string{"a", "b"}, false)
+	successfulAssert.Decreasing([3]int64{3, 2, 2}, false)
+	failingAssert.Decreasing([]int{3, 2, 2}, true)
+
+	// Failure details.
+	assert, failures := asserts.NewValidation()
+	assert.Increasing([]int{1, 3, 2}, false)
+	assert.Range(int64(0), 1, 10)
+	assert.Positive(-1)
+	assert.Range(int64(1<<53+1), float64(1<<53), float64(1<<53))
+	assert.AboutRel(uint64(1<<60+1), uint64(1<<60), 0)
+	assert.AboutULP(1.0, 1, 1, "ulp")
+	assert.Increasing([]string{"a", "b"}, false, "order")
+	details := failures.Details()
+	successfulAssert.Length(details, 7)
+	successfulAssert.Equal(details[0].Error().Error(), "assert 'increasing' failed: '[1 3 2]' is not increasing: [2]: 2 after [1]: 3")
+	successfulAssert.Equal(details[1].Error().Error(), "assert 'range' failed: not '1' <= '0' <= '10'")
+	successfulAssert.Equal(details[2].Error().Error(), "assert 'positive' failed: '-1'")
+	successfulAssert.Equal(details[3].Error().Error(), "assert 'range' failed: not '9.007199254740992e+15' <= '9007199254740993' <= '9.007199254740992e+15'")
+	successfulAssert.Equal(details[4].Test(), asserts.AboutRel)
+	successfulAssert.Match(details[5].Message(), "ulp type missmatch: .*")
+	successfulAssert.Match(details[6].Message(), "order type missmatch: .*")
+}
+
+// TestStringAsserts tests the assertions on strings and the
+// diff of multi-line strings.
+func TestStringAsserts(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	successfulAssert.HasPrefix("golang", "go")
+	successfulAssert.HasPrefix("golang", "")
+	failingAssert.HasPrefix("golang", "lang")
+	successfulAssert.HasSuffix("golang", "lang")
+	failingAssert.HasSuffix("golang", "go")
+	successfulAssert.EqualFold("Straße", "STRAßE")
+	successfulAssert.EqualFold("Go", "GO")
+	failingAssert.EqualFold("Go", "Goo")
+	successfulAssert.EqualNormalized("  one\ttwo\n\nthree ", "one two three")
+	successfulAssert.EqualNormalized("", " \n ")
+	failingAssert.EqualNormalized("one two", "onetwo")
+
+	// Unified diff of multi-line strings.
+	bp := asserts.NewBufferedPrinter()
+	assert := asserts.NewTesting(t, asserts.NoFailing)
+	assert.SetPrinter(bp)
+	obtained := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\tk \r"
+	expected := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\tk"
+	assert.Equal(obtained, expected)
+	b := bp.Flush()
+	successfulAssert.Length(b, 1)
+	successfulAssert.Contains("{diff: 4 changed line(s)}\n", b[0])
+	successfulAssert.Contains(
+		"\t@@ -1,4 +1,4 @@\n\t a\n\t-B\n\t+b\n\t c\n\t d\n"+
+			"\t@@ -8,3 +8,3 @@\n\t h\n\t i\n\t-j\\tk\n\t+j\\tk·\\r\n", b[0])
+
+	assert, failures := asserts.NewValidation()
+	assert.Equal("one\ntwo", "one\nthree")
+	assert.HasPrefix("golang", "lang")
+	details := failures.Details()
+	successfulAssert.Length(details, 2)
+	successfulAssert.Equal(details[0].Error().Error(), "assert 'equal' failed: multi-line strings differ in 2 line(s)")
+	successfulAssert.Equal(details[0].Diff(), []string{"@@ -1,2 +1,2 @@", " one", "-three", "+two"})
+	successfulAssert.Equal(details[1].Error().Error(), "assert 'has prefix' failed: 'golang' has no prefix 'lang'")
+}
+
+// TestGoroutineLeak tests the detection of leaked goroutines.
+func TestGoroutineLeak(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	successfulAssert.NoGoroutineLeak(func() {}, 0, nil)
+	successfulAssert.NoGoroutineLeak(func() {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+		}()
+	}, time.Second, nil)
+
+	stopc := make(chan struct{})
+	defer close(stopc)
+	leak := func() {
+		go func() {
+			<-stopc
+		}()
+	}
+	failingAssert.NoGoroutineLeak(leak, 20*time.Millisecond, nil)
+	successfulAssert.NoGoroutineLeak(leak, 20*time.Millisecond, []string{`TestGoroutineLeak\.func`})
+	failingAssert.NoGoroutineLeak(func() {}, 0, []string{"("})
+
+	snapshot := asserts.NewGoroutineSnapshot()
+	leak()
+	failingAssert.NoNewGoroutines(snapshot, 0, nil)
+
+	// Leaked stacks in the failure.
+	assert, failures := asserts.NewValidation()
+	assert.NoGoroutineLeak(leak, 0, nil, "leaking")
+	details := failures.Details()
+	successfulAssert.Length(details, 1)
+	successfulAssert.Match(details[0].Error().Error(),
+		`assert 'goroutine leak' failed: 1 goroutine\(s\) leaked after '0s': goroutine [0-9]+ \[[a-z ]+\]:; .*TestGoroutineLeak.*`)
+	successfulAssert.Equal(details[0].Message(), "leaking")
+
+	// Invalid patterns are reported before running the function.
+	called := false
+	assert.NoGoroutineLeak(func() { called = true }, 0, []string{"("}, "invalid")
+	details = failures.Details()
+	successfulAssert.Length(details, 2)
+	successfulAssert.False(called)
+	successfulAssert.Equal(details[1].Obtained(), "(")
+	successfulAssert.Match(details[1].Message(), "invalid can't compile regex: .*")
+}
+
+// TestChannelAsserts tests the assertions on channels of any type.
+func TestChannelAsserts(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+
+	intc := make(chan int, 5)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			intc <- i
+		}
+	}()
+	successfulAssert.True(asserts.ReceiveT(successfulAssert, intc, []int{1, 2, 3}, time.Second))
+	successfulAssert.True(asserts.ReceiveT(successfulAssert, intc, nil, 0))
+	failingAssert.False(asserts.ReceiveT(failingAssert, intc, []int{4}, 10*time.Millisecond))
+	intc <- 1
+	intc <- 5
+	failingAssert.False(asserts.ReceiveT(failingAssert, intc, []int{1, 2, 3}, time.Second))
+
+	successfulAssert.True(asserts.NoReceiveT(successfulAssert, intc, 10*time.Millisecond))
+	intc <- 1
+	successfulAssert.True(asserts.BufferedT(successfulAssert, intc, 1))
+	failingAssert.False(asserts.BufferedT(failingAssert, intc, 2))
+	failingAssert.False(asserts.NoReceiveT(failingAssert, intc, time.Second))
+	successfulAssert.True(asserts.BufferedT(successfulAssert, intc, 0))
+
+	successfulAssert.True(asserts.ReceivableT(successfulAssert, intc))
+	failingAssert.False(asserts.ClosedT(failingAssert, intc))
+	intc <- 1
+	successfulAssert.True(asserts.ReceivableT(successfulAssert, intc))
+	failingAssert.False(asserts.ClosedT(failingAssert, intc))
+	close(intc)
+	successfulAssert.True(asserts.ClosedT(successfulAssert, intc))
+	failingAssert.False(asserts.ReceivableT(failingAssert, intc))
+
+	bufc := make(chan int, 1)
+	bufc <- 1
+	close(bufc)
+	successfulAssert.True(asserts.ReceivableT(successfulAssert, bufc))
+	failingAssert.False(asserts.ClosedT(failingAssert, bufc))
+	successfulAssert.True(asserts.ClosedT(successfulAssert, bufc))
+	failingAssert.False(asserts.ReceivableT(failingAssert, bufc))
+	failingAssert.False(asserts.NoReceiveT(failingAssert, intc, time.Second))
+	failingAssert.False(asserts.ReceiveT(failingAssert, intc, []int{1}, time.Second))
+
+	type event struct {
+		name string
+		tags []string
+	}
+	eventc := make(chan event)
+	go func() {
+		eventc <- event{"start", []string{"a"}}
+		eventc <- event{"stop", nil}
+	}()
+	successfulAssert.True(asserts.ReceiveT(successfulAssert, eventc, []event{{"start", []string{"a"}}, {"stop", nil}}, time.Second))
+
+	// Failure details.
+	assert, failures := asserts.NewValidation()
+	strc := make(chan string, 2)
+	strc <- "a"
+	asserts.ReceiveT(assert, strc, []string{"a", "b"}, 10*time.Millisecond)
+	strc <- "x"
+	asserts.ReceiveT(assert, strc, []string{"a"}, time.Second)
+	close(strc)
+	asserts.ReceiveT(assert, strc, []string{"a"}, time.Second)
+	asserts.NoReceiveT(assert, strc, time.Second)
+	details := failures.Details()
+	successfulAssert.Length(details, 4)
+	successfulAssert.Equal(details[0].Error().Error(), "assert 'receive' failed: 'timeout 10ms' <> '[a b]' (received [a])")
+	successfulAssert.Equal(details[1].Error().Error(), "assert 'receive' failed: '[x]' <> '[a]'")
+	successfulAssert.Equal(details[2].Error().Error(), "assert 'receive' failed: 'closed' <> '[a]' (received [])")
+	successfulAssert.Equal(details[3].Error().Error(), "assert 'no receive' failed: 'closed' <> 'nothing within 1s'")
+}
+
+// TestAssertWaitCtx tests the context variants of the waiting assertions.
+func TestAssertWaitCtx(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+	timeoutCtx := func() context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		t.Cleanup(cancel)
+		return ctx
+	}
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sigc := asserts.MakeWaitChan()
+	go func() {
+		sigc <- true
+	}()
+	successfulAssert.WaitCtx(timeoutCtx(), sigc, true)
+	failingAssert.WaitCtx(timeoutCtx(), sigc, true)
+	failingAssert.WaitCtx(cancelledCtx, sigc, true)
+
+	go func() {
+		sigc <- 42
+	}()
+	successfulAssert.WaitTestedCtx(context.Background(), sigc, func(v any) error {
+		if v != 42 {
+			return errors.New("not 42")
+		}
+		return nil
+	})
+	failingAssert.WaitTestedCtx(timeoutCtx(), sigc, func(v any) error { return nil })
+
+	closec := asserts.MakeWaitChan()
+	go func() {
+		closec <- 1
+		close(closec)
+	}()
+	successfulAssert.WaitClosedCtx(timeoutCtx(), closec)
+	failingAssert.WaitClosedCtx(timeoutCtx(), sigc)
+
+	successfulAssert.WaitGroupCtx(timeoutCtx(), &sync.WaitGroup{})
+	var pendingWG sync.WaitGroup
+	pendingWG.Add(1)
+	failingAssert.WaitGroupCtx(timeoutCtx(), &pendingWG)
+	pendingWG.Done()
+
+	calls := 0
+	successfulAssert.RetryCtx(context.Background(), func() bool {
+		calls++
+		return calls == 3
+	}, 5, time.Millisecond)
+	failingAssert.RetryCtx(context.Background(), func() bool { return false }, 3, time.Millisecond)
+	failingAssert.RetryCtx(timeoutCtx(), func() bool { return false }, 1000, 5*time.Millisecond)
+
+	// Failures tell cancellation and deadline.
+	assert, failures := asserts.NewValidation()
+	assert.WaitCtx(cancelledCtx, sigc, true)
+	assert.WaitGroupCtx(timeoutCtx(), &sync.WaitGroup{})
+	var timeoutWG sync.WaitGroup
+	timeoutWG.Add(1)
+	assert.WaitGroupCtx(timeoutCtx(), &timeoutWG)
+	timeoutWG.Done()
+	assert.RetryCtx(cancelledCtx, func() bool { return false }, 10, time.Second)
+	details := failures.Details()
+	successfulAssert.Length(details, 3)
+	successfulAssert.Match(details[0].Error().Error(), `assert 'wait' failed: 'context canceled after .+' <> 'signal true'`)
+	successfulAssert.Match(details[1].Error().Error(), `assert 'wait group' failed: 'context deadline exceeded after .+' <> 'done'`)
+	successfulAssert.Match(details[2].Error().Error(), `assert 'retry' failed: 'context canceled after .+ and 1 retries' <> 'successful call'`)
+}
+
+// TestPanicAsserts tests the matching of panics and the
+// stack of unexpected ones.
+func TestPanicAsserts(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	failingAssert := failingAsserts(t)
+	errOuch := errors.New("ouch")
+
+	successfulAssert.PanicsMatching(func() { panic("ouch: 42") }, "ouch: [0-9]+")
+	successfulAssert.PanicsMatching(func() { panic(fmt.Errorf("wrapped: %w", errOuch)) }, "wrapped: ouch")
+	successfulAssert.PanicsMatching(func() { panic(42) }, "4.")
+	failingAssert.PanicsMatching(func() {}, ".*")
+	failingAssert.PanicsMatching(func() { panic("ouch") }, "auch")
+	failingAssert.PanicsMatching(func() { panic("ouch") }, "(")
+
+	successfulAssert.PanicsWithError(func() { panic(errOuch) }, errOuch)
+	successfulAssert.PanicsWithError(func() { panic(fmt.Errorf("wrapped: %w", errOuch)) }, errOuch)
+	failingAssert.PanicsWithError(func() {}, errOuch)
+	failingAssert.PanicsWithError(func() { panic("ouch") }, errOuch)
+	failingAssert.PanicsWithError(func() { panic(errors.New("ouch")) }, errOuch)
+
+	// Recovered value and stack of unexpected panics.
+	assert, failures := asserts.NewValidation()
+	assert.NotPanics(func() { panic("ouch") })
+	panicLocation := callerLocation(-1)
+	assert.PanicsWithError(func() { panic(fmt.Errorf("wrapped: %w", errors.New("other"))) }, errOuch)
+	assert.PanicsMatching(func() { panic("ouch") }, "(", "regex")
+	assert.PanicsWithError(func() { panic("ouch") }, errOuch, "error")
+	details := failures.Details()
+	successfulAssert.Length(details, 4)
+	successfulAssert.Match(details[0].Error().Error(),
+		`assert 'not panics' failed: panic 'ouch' in \S*TestPanicAsserts\.func\S* at `+strings.TrimSuffix(panicLocation, ":0:"))
+	pi, ok := details[0].Obtained().(*asserts.PanicInfo)
+	successfulAssert.True(ok)
+	successfulAssert.Equal(pi.Value, "ouch")
+	successfulAssert.Substring("TestPanicAsserts.func", pi.Stack)
+	successfulAssert.Equal(details[0].Diff(), strings.Split(pi.Stack, "\n"))
+	successfulAssert.Equal(details[1].Error().Error(),
+		"assert 'panics with error' failed: 'wrapped: other' <> 'ouch' with chain *fmt.wrapError: wrapped: other; *errors.errorString: other")
+	successfulAssert.Match(details[2].Message(), `regex can't compile regex: .*`)
+	successfulAssert.Equal(details[3].Message(), "error reason is no error")
+}
+
 //--------------------
 // META FAILER
 //--------------------
@@ -757,6 +2029,13 @@ func (f *metaFailer) Fail(test asserts.Test, obtained, expected any, msgs ...str
 // HELPER
 //--------------------
 
+// callerLocation returns the location of the caller's line moved
+// by the delta, formatted like the locations of the failers.
+func callerLocation(delta int) string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d:0:", filepath.Base(file), line+delta)
+}
+
 // failWithOffset checks the offset increment.
 func failWithOffset(assert *asserts.Asserts, line string) {
 	restore := assert.IncrCallstackOffset()
@@ -765,6 +2044,27 @@ func failWithOffset(assert *asserts.Asserts, line string) {
 	assert.Fail("should fail referencing line " + line)
 }
 
+// matchSnapshot matches a snapshot on behalf of its caller.
+func matchSnapshot(assert *asserts.Asserts, obtained any) bool {
+	restore := assert.IncrCallstackOffset()
+	defer restore()
+
+	return assert.MatchSnapshot(obtained)
+}
+
+// evenTest is the registered test of assertEven().
+var evenTest = asserts.RegisterFormattedTest("even", func(obtained, expected any) string {
+	return fmt.Sprintf("%v is odd", obtained)
+})
+
+// assertEven is an own assertion using a registered test.
+func assertEven(assert *asserts.Asserts, obtained int, msgs ...string) bool {
+	if obtained%2 != 0 {
+		return assert.Report(evenTest, obtained, nil, msgs...)
+	}
+	return true
+}
+
 // successfulAsserts returns an Asserts insrance which doesn't expect a failing.
 func successfulAsserts(t *testing.T) *asserts.Asserts {
 	return asserts.New(&metaFailer{t, true})
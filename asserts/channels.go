@@ -0,0 +1,115 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"fmt"
+	"time"
+)
+
+//--------------------
+// CHANNEL ASSERTS
+//--------------------
+
+// The channel assertions work on channels of any type. Like the
+// generic assertions they are functions taking the Asserts instance
+// as first argument.
+//
+//	asserts.ReceiveT(assert, eventc, []Event{started, stopped}, time.Second)
+//	asserts.NoReceiveT(assert, errc, 100*time.Millisecond)
+
+// ReceiveT receives the expected values in the given order from the
+// channel. The assert fails on the first different value, if the channel
+// is closed before, or if not all values are received within the timeout.
+func ReceiveT[E any](a *Asserts, ch <-chan E, expected []E, timeout time.Duration, msgs ...string) bool {
+	done := time.NewTimer(timeout)
+	defer done.Stop()
+	received := []E{}
+	for len(received) < len(expected) {
+		select {
+		case obtained, ok := <-ch:
+			if !ok {
+				return a.failer.Fail(Receive, "closed", expected, appendMsg(msgs, fmt.Sprintf("received %v", received))...)
+			}
+			received = append(received, obtained)
+			if !isEqual(obtained, expected[len(received)-1]) {
+				return a.failer.Fail(Receive, received, expected, msgs...)
+			}
+		case <-done.C:
+			return a.failer.Fail(Receive, "timeout "+timeout.String(), expected, appendMsg(msgs, fmt.Sprintf("received %v", received))...)
+		}
+	}
+	return true
+}
+
+// NoReceiveT tests if nothing is received from the channel during
+// the period. A closed channel lets the assert fail too.
+func NoReceiveT[E any](a *Asserts, ch <-chan E, period time.Duration, msgs ...string) bool {
+	done := time.NewTimer(period)
+	defer done.Stop()
+	select {
+	case obtained, ok := <-ch:
+		if !ok {
+			return a.failer.Fail(NoReceive, "closed", "nothing within "+period.String(), msgs...)
+		}
+		return a.failer.Fail(NoReceive, obtained, "nothing within "+period.String(), msgs...)
+	case <-done.C:
+		return true
+	}
+}
+
+// ClosedT tests without blocking if the channel is closed. Checking
+// it means receiving, so a buffered or concurrently sent value is
+// consumed and lets the assert fail.
+func ClosedT[E any](a *Asserts, ch <-chan E, msgs ...string) bool {
+	select {
+	case obtained, ok := <-ch:
+		if ok {
+			return a.failer.Fail(Closed, obtained, "closed", msgs...)
+		}
+		return true
+	default:
+		return a.failer.Fail(Closed, "open", "closed", msgs...)
+	}
+}
+
+// ReceivableT tests without blocking if values still can be received
+// from the channel. This is the case if values are buffered, even if
+// the channel already is closed, or if the channel is open. Checking
+// an empty channel means receiving. So it is only valid if no other
+// goroutine sends to the channel concurrently, as such a value would
+// be consumed and lost.
+func ReceivableT[E any](a *Asserts, ch <-chan E, msgs ...string) bool {
+	if len(ch) > 0 {
+		return true
+	}
+	select {
+	case _, ok := <-ch:
+		if !ok {
+			return a.failer.Fail(Receivable, "closed", "receivable", msgs...)
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// BufferedT tests if the number of values buffered in
+// the channel is equal to the expected one.
+func BufferedT[E any](a *Asserts, ch <-chan E, expected int, msgs ...string) bool {
+	if len(ch) != expected {
+		return a.failer.Fail(Buffered, len(ch), expected, msgs...)
+	}
+	return true
+}
+
+// EOF
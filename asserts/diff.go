@@ -0,0 +1,667 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//--------------------
+// DIFF
+//--------------------
+
+// failureDiff returns the differences between obtained and expected
+// for those tests where a structural diff helps reading the failure.
+// In case of error tests it is the chain of wrapped errors.
+func failureDiff(test Test, obtained, expected any) []string {
+	switch test {
+	case Equal:
+		if isText(obtained, expected) {
+			return textDiff(obtained.(string), expected.(string))
+		}
+		if !isDiffable(obtained) && !isDiffable(expected) {
+			return nil
+		}
+		return valueDiff(obtained, expected, nil)
+	case EqualWith:
+		if ov, ok := expected.(*optionsValue); ok {
+			return valueDiff(obtained, ov.value, ov.options)
+		}
+	case JSONEqual:
+		od, oerr := jsonDocument(obtained)
+		ed, eerr := jsonDocument(expected)
+		if oerr == nil && eerr == nil {
+			return jsonDiff("$", od, ed)
+		}
+	case JSONPath:
+		if jpv, ok := expected.(*jsonPathValue); ok {
+			return jsonDiff(jpv.path, obtained, jpv.value)
+		}
+	case ErrorIs, ErrorAs, PanicsWithError:
+		if err, ok := obtained.(error); ok {
+			return errorChain(err, "")
+		}
+	case Golden, Snapshot:
+		ostr, ook := obtained.(string)
+		estr, eok := expected.(string)
+		if ook && eok {
+			return lineDiff(ostr, estr)
+		}
+	case ContainsAll, ElementsMatch, Subset:
+		missing, extra, err := unmatchedElements(obtained, expected, test == ElementsMatch)
+		if err == nil {
+			switch test {
+			case ContainsAll:
+				extra = nil
+			case Subset:
+				missing = nil
+			}
+			return elementLines(missing, extra)
+		}
+	case Unique:
+		duplicates, err := duplicateElements(obtained)
+		if err == nil {
+			return duplicates
+		}
+	case KeysEqual:
+		diff, err := keysDiff(obtained, expected)
+		if err == nil {
+			return diff
+		}
+	case MapSubset:
+		diff, err := mapSubsetDiff(obtained, expected)
+		if err == nil {
+			return diff
+		}
+	case NotPanics:
+		if pi, ok := obtained.(*PanicInfo); ok {
+			return strings.Split(pi.Stack, "\n")
+		}
+	case GoroutineLeak:
+		if stacks, ok := obtained.([]string); ok {
+			lines := []string{}
+			for _, stack := range stacks {
+				lines = append(lines, strings.Split(stack, "\n")...)
+			}
+			return lines
+		}
+	case Sorted, Increasing, Decreasing:
+		ov := reflect.ValueOf(obtained)
+		if index, ok := expected.(int); ok && isDiffable(obtained) && index > 0 && index < ov.Len() {
+			return []string{fmt.Sprintf("[%d]: %v after [%d]: %v", index, ov.Index(index), index-1, ov.Index(index-1))}
+		}
+	}
+	return nil
+}
+
+// elementLines returns one line per missing and per extra element.
+func elementLines(missing, extra []any) []string {
+	lines := []string{}
+	for _, e := range missing {
+		lines = append(lines, fmt.Sprintf("missing: %v", e))
+	}
+	for _, e := range extra {
+		lines = append(lines, fmt.Sprintf("extra: %v", e))
+	}
+	return lines
+}
+
+// keysDiff compares the keys of the obtained map with the expected keys,
+// passed as array, slice, or map. It returns one line per missing and
+// per unexpected key.
+func keysDiff(obtained, expected any) ([]string, error) {
+	ov := reflect.ValueOf(obtained)
+	if ov.Kind() != reflect.Map {
+		return nil, fmt.Errorf("obtained %s is no map", ValueDescription(obtained))
+	}
+	ev := reflect.ValueOf(expected)
+	var expectedKeys []reflect.Value
+	switch ev.Kind() {
+	case reflect.Map:
+		expectedKeys = ev.MapKeys()
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < ev.Len(); i++ {
+			expectedKeys = append(expectedKeys, ev.Index(i))
+		}
+	default:
+		return nil, fmt.Errorf("expected %s is no array, slice, or map", ValueDescription(expected))
+	}
+	keyType := ov.Type().Key()
+	expectedIDs := make(map[string]bool)
+	missing := []string{}
+	for _, key := range expectedKeys {
+		if key.Kind() == reflect.Interface {
+			key = key.Elem()
+		}
+		if !key.IsValid() || !key.Type().AssignableTo(keyType) {
+			return nil, fmt.Errorf("expected key %v is no %v", key, keyType)
+		}
+		id := diffKey(key)
+		expectedIDs[id] = true
+		if !ov.MapIndex(key).IsValid() {
+			missing = append(missing, "missing key: "+id)
+		}
+	}
+	unexpected := []string{}
+	for _, key := range ov.MapKeys() {
+		if id := diffKey(key); !expectedIDs[id] {
+			unexpected = append(unexpected, "unexpected key: "+id)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return append(missing, unexpected...), nil
+}
+
+// mapSubsetDiff compares the entries of the expected map with those
+// of the obtained map. It returns one line per missing key and per
+// difference of the values, prefixed by their key path.
+func mapSubsetDiff(obtained, expected any) ([]string, error) {
+	ov := reflect.ValueOf(obtained)
+	ev := reflect.ValueOf(expected)
+	if ov.Kind() != reflect.Map {
+		return nil, fmt.Errorf("obtained %s is no map", ValueDescription(obtained))
+	}
+	if ev.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected %s is no map", ValueDescription(expected))
+	}
+	if ov.Type().Key() != ev.Type().Key() {
+		return nil, fmt.Errorf("key types %v and %v differ", ov.Type().Key(), ev.Type().Key())
+	}
+	d := &differ{
+		visited: make(map[visit]bool),
+	}
+	keys := ev.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return diffKey(keys[i]) < diffKey(keys[j])
+	})
+	for _, key := range keys {
+		keyPath := "[" + diffKey(key) + "]"
+		ovv := ov.MapIndex(key)
+		evv := ev.MapIndex(key)
+		if !ovv.IsValid() {
+			d.add(keyPath, "<missing>", diffValue(evv))
+			continue
+		}
+		d.walk(keyPath, ovv, evv)
+	}
+	return d.diffs, nil
+}
+
+// isDiffable checks if a value is a struct, map, array, or slice or
+// a pointer to one of them.
+func isDiffable(value any) bool {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+// valueDiff walks obtained and expected and returns one line per
+// difference, each one prefixed by its field path like
+// `.Orders[3].Items["sku"].Qty: got 2, want 3`. The optional
+// options relax the comparison.
+func valueDiff(obtained, expected any, eo *equalOptions) []string {
+	d := &differ{
+		options: eo,
+		visited: make(map[visit]bool),
+	}
+	d.walk("", reflect.ValueOf(obtained), reflect.ValueOf(expected))
+	return d.diffs
+}
+
+// visit marks a pair of already compared pointers to stop cycles.
+type visit struct {
+	obtained uintptr
+	expected uintptr
+	typ      reflect.Type
+}
+
+// differ collects the differences found while walking two values.
+type differ struct {
+	options *equalOptions
+	visited map[visit]bool
+	diffs   []string
+}
+
+// add appends a difference for the given path.
+func (d *differ) add(path, obtained, expected string) {
+	if path == "" {
+		path = "."
+	}
+	d.diffs = append(d.diffs, fmt.Sprintf("%s: got %s, want %s", path, obtained, expected))
+}
+
+// addElement appends an unmatched element of an unordered
+// comparison for the given path.
+func (d *differ) addElement(path, kind string, value reflect.Value) {
+	if path == "" {
+		path = "."
+	}
+	d.diffs = append(d.diffs, fmt.Sprintf("%s: %s %s", path, kind, diffValue(value)))
+}
+
+// walk compares obtained and expected recursively.
+func (d *differ) walk(path string, obtained, expected reflect.Value) {
+	if !obtained.IsValid() || !expected.IsValid() {
+		if obtained.IsValid() != expected.IsValid() {
+			d.add(path, diffValue(obtained), diffValue(expected))
+		}
+		return
+	}
+	if obtained.Type() != expected.Type() {
+		d.add(path, diffTypedValue(obtained), diffTypedValue(expected))
+		return
+	}
+	if obtained.CanInterface() && expected.CanInterface() &&
+		reflect.DeepEqual(obtained.Interface(), expected.Interface()) {
+		return
+	}
+	if equal, used := d.options.equalByMethod(obtained, expected); used {
+		if !equal {
+			d.add(path, diffValue(obtained), diffValue(expected))
+		}
+		return
+	}
+	switch obtained.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if obtained.IsNil() || expected.IsNil() {
+			if obtained.IsNil() != expected.IsNil() {
+				d.add(path, diffValue(obtained), diffValue(expected))
+			}
+			return
+		}
+		if obtained.Kind() == reflect.Ptr {
+			v := visit{obtained.Pointer(), expected.Pointer(), obtained.Type()}
+			if d.visited[v] {
+				return
+			}
+			d.visited[v] = true
+		}
+		d.walk(path, obtained.Elem(), expected.Elem())
+	case reflect.Struct:
+		if !hasExportedFields(obtained.Type()) && obtained.CanInterface() {
+			// Opaque types like time.Time are compared as a whole, also
+			// if unexported fields are ignored, as otherwise they would
+			// always be equal.
+			d.add(path, diffValue(obtained), diffValue(expected))
+			return
+		}
+		for i := 0; i < obtained.NumField(); i++ {
+			field := obtained.Type().Field(i)
+			if d.options.ignoresField(obtained.Type(), field) {
+				continue
+			}
+			d.walk(path+"."+field.Name, obtained.Field(i), expected.Field(i))
+		}
+	case reflect.Map:
+		if obtained.IsNil() != expected.IsNil() {
+			d.add(path, diffValue(obtained), diffValue(expected))
+			return
+		}
+		for _, key := range mergedKeys(obtained, expected) {
+			keyPath := path + "[" + diffKey(key) + "]"
+			ov := obtained.MapIndex(key)
+			ev := expected.MapIndex(key)
+			switch {
+			case !ov.IsValid():
+				d.add(keyPath, "<missing>", diffValue(ev))
+			case !ev.IsValid():
+				d.add(keyPath, diffValue(ov), "<missing>")
+			default:
+				d.walk(keyPath, ov, ev)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if obtained.Kind() == reflect.Slice && obtained.IsNil() != expected.IsNil() {
+			d.add(path, diffValue(obtained), diffValue(expected))
+			return
+		}
+		if d.options != nil && d.options.unorderedSlices {
+			d.unorderedWalk(path, obtained, expected)
+			return
+		}
+		ol := obtained.Len()
+		el := expected.Len()
+		for i := 0; i < ol || i < el; i++ {
+			indexPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= ol:
+				d.add(indexPath, "<missing>", diffValue(expected.Index(i)))
+			case i >= el:
+				d.add(indexPath, diffValue(obtained.Index(i)), "<missing>")
+			default:
+				d.walk(indexPath, obtained.Index(i), expected.Index(i))
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		if !d.options.equalFloats(obtained.Float(), expected.Float()) {
+			d.add(path, diffValue(obtained), diffValue(expected))
+		}
+	default:
+		if !isEqualValue(obtained, expected) {
+			d.add(path, diffValue(obtained), diffValue(expected))
+		}
+	}
+}
+
+// unorderedWalk compares two slices or arrays as multisets and
+// reports the unmatched elements of both sides. Indexes would not
+// point to the same elements there, so only the values are shown.
+func (d *differ) unorderedWalk(path string, obtained, expected reflect.Value) {
+	matched := make([]bool, expected.Len())
+	for i := 0; i < obtained.Len(); i++ {
+		found := false
+		for j := 0; j < expected.Len(); j++ {
+			if matched[j] {
+				continue
+			}
+			sub := &differ{
+				options: d.options,
+				visited: make(map[visit]bool),
+			}
+			sub.walk("", obtained.Index(i), expected.Index(j))
+			if len(sub.diffs) == 0 {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			d.addElement(path, "extra", obtained.Index(i))
+		}
+	}
+	for j := range matched {
+		if !matched[j] {
+			d.addElement(path, "missing", expected.Index(j))
+		}
+	}
+}
+
+// isEqualValue compares two simple values of the same type, also
+// when they are unexported and cannot be accessed as interface.
+func isEqualValue(obtained, expected reflect.Value) bool {
+	switch obtained.Kind() {
+	case reflect.Bool:
+		return obtained.Bool() == expected.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return obtained.Int() == expected.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return obtained.Uint() == expected.Uint()
+	case reflect.Float32, reflect.Float64:
+		return obtained.Float() == expected.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return obtained.Complex() == expected.Complex()
+	case reflect.String:
+		return obtained.String() == expected.String()
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return obtained.Pointer() == expected.Pointer()
+	}
+	return false
+}
+
+// hasExportedFields checks if a struct type has at least one exported field.
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedKeys returns the sorted keys of both maps.
+func mergedKeys(obtained, expected reflect.Value) []reflect.Value {
+	seen := make(map[string]bool)
+	keys := []reflect.Value{}
+	for _, m := range []reflect.Value{obtained, expected} {
+		for _, key := range m.MapKeys() {
+			id := diffKey(key)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return diffKey(keys[i]) < diffKey(keys[j])
+	})
+	return keys
+}
+
+// diffKey formats a map key for a diff path.
+func diffKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return strconv.Quote(key.String())
+	}
+	return fmt.Sprintf("%v", key)
+}
+
+// diffValue formats a value for a diff line.
+func diffValue(value reflect.Value) string {
+	if !value.IsValid() {
+		return "<nil>"
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return strconv.Quote(value.String())
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		if value.IsNil() {
+			return "<nil>"
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// diffTypedValue formats a value including its type for a diff line.
+func diffTypedValue(value reflect.Value) string {
+	if !value.IsValid() {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s (%s)", diffValue(value), value.Type())
+}
+
+// lineDiff compares obtained and expected text line by line. The
+// result contains the differing lines prefixed with their line
+// numbers, "-" for expected lines missing in obtained and "+" for
+// obtained lines not expected.
+func lineDiff(obtained, expected string) []string {
+	diffs := []string{}
+	for _, edit := range lineEdits(obtained, expected) {
+		switch edit.op {
+		case '-':
+			diffs = append(diffs, fmt.Sprintf("-%d: %s", edit.eindex+1, edit.text))
+		case '+':
+			diffs = append(diffs, fmt.Sprintf("+%d: %s", edit.oindex+1, edit.text))
+		}
+	}
+	return diffs
+}
+
+// textContext is the number of unchanged lines shown
+// around the changes of a text diff.
+const textContext = 2
+
+// textDiff compares obtained and expected text line by line and returns
+// a unified diff. Control characters are escaped and trailing spaces are
+// marked to make them visible.
+func textDiff(obtained, expected string) []string {
+	edits := lineEdits(obtained, expected)
+	diffs := []string{}
+	for start := 0; start < len(edits); {
+		// Find the changes of the next hunk.
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for i := first + 1; i < len(edits); i++ {
+			if edits[i].op == ' ' {
+				continue
+			}
+			if i-last > 2*textContext {
+				break
+			}
+			last = i
+		}
+		from := first - textContext
+		if from < start {
+			from = start
+		}
+		to := last + textContext + 1
+		if to > len(edits) {
+			to = len(edits)
+		}
+		// Add the hunk with its header.
+		ecount, ocount := 0, 0
+		lines := []string{}
+		for _, edit := range edits[from:to] {
+			if edit.op != '+' {
+				ecount++
+			}
+			if edit.op != '-' {
+				ocount++
+			}
+			lines = append(lines, string(edit.op)+visibleLine(edit.text))
+		}
+		header := fmt.Sprintf("@@ -%d,%d +%d,%d @@", edits[from].eindex+1, ecount, edits[from].oindex+1, ocount)
+		diffs = append(diffs, header)
+		diffs = append(diffs, lines...)
+		start = to
+	}
+	return diffs
+}
+
+// lineEdit is one line of the comparison of obtained and expected text.
+// Its operation is ' ' for common lines, '-' for expected lines missing
+// in obtained, and '+' for obtained lines not expected.
+type lineEdit struct {
+	op     byte
+	oindex int
+	eindex int
+	text   string
+}
+
+// lineEdits compares obtained and expected text line by line based
+// on the longest common subsequence of their lines.
+func lineEdits(obtained, expected string) []lineEdit {
+	ols := strings.Split(obtained, "\n")
+	els := strings.Split(expected, "\n")
+	// Skip common prefix and suffix.
+	prefix := 0
+	for prefix < len(ols) && prefix < len(els) && ols[prefix] == els[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(ols)-prefix && suffix < len(els)-prefix &&
+		ols[len(ols)-1-suffix] == els[len(els)-1-suffix] {
+		suffix++
+	}
+	edits := []lineEdit{}
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, lineEdit{' ', i, i, ols[i]})
+	}
+	mols := ols[prefix : len(ols)-suffix]
+	mels := els[prefix : len(els)-suffix]
+	// Longest common subsequence of the remaining lines.
+	lcs := make([][]int, len(mols)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mels)+1)
+	}
+	for i := len(mols) - 1; i >= 0; i-- {
+		for j := len(mels) - 1; j >= 0; j-- {
+			if mols[i] == mels[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	// Walk it and collect the edits.
+	i, j := 0, 0
+	for i < len(mols) || j < len(mels) {
+		switch {
+		case i < len(mols) && j < len(mels) && mols[i] == mels[j]:
+			edits = append(edits, lineEdit{' ', prefix + i, prefix + j, mols[i]})
+			i++
+			j++
+		case j < len(mels) && (i == len(mols) || lcs[i][j+1] >= lcs[i+1][j]):
+			edits = append(edits, lineEdit{'-', prefix + i, prefix + j, mels[j]})
+			j++
+		default:
+			edits = append(edits, lineEdit{'+', prefix + i, prefix + j, mols[i]})
+			i++
+		}
+	}
+	for k := len(ols) - suffix; k < len(ols); k++ {
+		edits = append(edits, lineEdit{' ', k, k - len(ols) + len(els), ols[k]})
+	}
+	return edits
+}
+
+// visibleLine escapes the control characters of a line and marks
+// the spaces of its trailing whitespace with middle dots.
+func visibleLine(line string) string {
+	trimmed := strings.TrimRightFunc(line, unicode.IsSpace)
+	var b strings.Builder
+	for i, r := range line {
+		switch {
+		case r == ' ' && i >= len(trimmed):
+			b.WriteString("·")
+		case unicode.IsControl(r):
+			b.WriteString(strings.Trim(strconv.QuoteRune(r), "'"))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isText checks if obtained and expected are strings
+// and at least one of them has multiple lines.
+func isText(obtained, expected any) bool {
+	ostr, ook := obtained.(string)
+	estr, eok := expected.(string)
+	return ook && eok && (strings.Contains(ostr, "\n") || strings.Contains(estr, "\n"))
+}
+
+// changedLines counts the removed and added lines of a text diff.
+func changedLines(diff []string) int {
+	n := 0
+	for _, line := range diff {
+		if strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+") {
+			n++
+		}
+	}
+	return n
+}
+
+// EOF
@@ -0,0 +1,198 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//--------------------
+// EQUAL OPTIONS
+//--------------------
+
+// EqualOption relaxes the comparison of Asserts.EqualWith().
+type EqualOption func(eo *equalOptions)
+
+// IgnoreFields lets the comparison ignore the struct fields with the
+// given names. A name can be qualified with the type name, e.g.
+// "Order.Created", otherwise the field is ignored in all structs.
+func IgnoreFields(names ...string) EqualOption {
+	return func(eo *equalOptions) {
+		for _, name := range names {
+			eo.ignoredFields[name] = true
+		}
+		eo.describe(fmt.Sprintf("ignore fields %v", names))
+	}
+}
+
+// IgnoreUnexported lets the comparison ignore all unexported struct fields.
+// Types having only unexported fields, like time.Time or big.Int, are
+// still compared as a whole or with TimeEquality() and EqualMethod().
+func IgnoreUnexported() EqualOption {
+	return func(eo *equalOptions) {
+		eo.ignoreUnexported = true
+		eo.describe("ignore unexported fields")
+	}
+}
+
+// UnorderedSlices lets the comparison treat slices and arrays as
+// multisets where the order of the elements does not matter.
+func UnorderedSlices() EqualOption {
+	return func(eo *equalOptions) {
+		eo.unorderedSlices = true
+		eo.describe("unordered slices")
+	}
+}
+
+// FloatTolerance lets the comparison accept floats differing
+// within the given epsilon.
+func FloatTolerance(epsilon float64) EqualOption {
+	return func(eo *equalOptions) {
+		eo.floatTolerance = math.Abs(epsilon)
+		eo.describe(fmt.Sprintf("float tolerance %v", eo.floatTolerance))
+	}
+}
+
+// TimeEquality lets the comparison check time.Time values with
+// their Equal() method, so location and monotonic clock reading
+// are ignored.
+func TimeEquality() EqualOption {
+	return func(eo *equalOptions) {
+		eo.timeEquality = true
+		eo.describe("time equality")
+	}
+}
+
+// EqualMethod lets the comparison use the method Equal() of a type
+// if it has one with the signature "func (T) Equal(T) bool".
+func EqualMethod() EqualOption {
+	return func(eo *equalOptions) {
+		eo.equalMethod = true
+		eo.describe("equal method")
+	}
+}
+
+// Message sets the message of a failing comparison like
+// the msgs of the other assertions.
+func Message(msgs ...string) EqualOption {
+	return func(eo *equalOptions) {
+		eo.msgs = append(eo.msgs, msgs...)
+	}
+}
+
+// equalOptions contains the options in force for a comparison.
+type equalOptions struct {
+	ignoredFields    map[string]bool
+	ignoreUnexported bool
+	unorderedSlices  bool
+	floatTolerance   float64
+	timeEquality     bool
+	equalMethod      bool
+	descriptions     []string
+	msgs             []string
+}
+
+// newEqualOptions creates the options out of the passed ones.
+func newEqualOptions(opts ...EqualOption) *equalOptions {
+	eo := &equalOptions{
+		ignoredFields: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(eo)
+	}
+	return eo
+}
+
+// describe adds the description of an option.
+func (eo *equalOptions) describe(description string) {
+	eo.descriptions = append(eo.descriptions, description)
+}
+
+// ignoresField checks if the field of the struct type has to be ignored.
+func (eo *equalOptions) ignoresField(t reflect.Type, field reflect.StructField) bool {
+	if eo == nil {
+		return false
+	}
+	if eo.ignoreUnexported && !field.IsExported() {
+		return true
+	}
+	return eo.ignoredFields[field.Name] || eo.ignoredFields[t.Name()+"."+field.Name]
+}
+
+// equalByMethod checks if obtained and expected are equal by using their
+// method Equal(). The second result tells if the method has been used.
+func (eo *equalOptions) equalByMethod(obtained, expected reflect.Value) (bool, bool) {
+	if eo == nil || !obtained.CanInterface() || !expected.CanInterface() {
+		return false, false
+	}
+	if eo.timeEquality {
+		if ot, ok := obtained.Interface().(time.Time); ok {
+			return ot.Equal(expected.Interface().(time.Time)), true
+		}
+	}
+	if !eo.equalMethod {
+		return false, false
+	}
+	method := obtained.MethodByName("Equal")
+	if !method.IsValid() {
+		return false, false
+	}
+	mt := method.Type()
+	if mt.NumIn() != 1 || mt.In(0) != obtained.Type() || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool {
+		return false, false
+	}
+	return method.Call([]reflect.Value{expected})[0].Bool(), true
+}
+
+// equalFloats checks if two floats are equal within the tolerance.
+func (eo *equalOptions) equalFloats(obtained, expected float64) bool {
+	if eo == nil {
+		return obtained == expected
+	}
+	return math.Abs(obtained-expected) <= eo.floatTolerance
+}
+
+// String implements fmt.Stringer.
+func (eo *equalOptions) String() string {
+	if eo == nil || len(eo.descriptions) == 0 {
+		return "none"
+	}
+	return strings.Join(eo.descriptions, ", ")
+}
+
+// optionsValue transports the expected value of an equality test
+// together with the options used for comparing.
+type optionsValue struct {
+	value   any
+	options *equalOptions
+}
+
+// String implements fmt.Stringer.
+func (ov *optionsValue) String() string {
+	return fmt.Sprintf("%v (options: %v)", ov.value, ov.options)
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// isEqualWith checks if obtained and expected are equal with
+// the given options in force.
+func isEqualWith(obtained, expected any, eo *equalOptions) bool {
+	return len(valueDiff(obtained, expected, eo)) == 0
+}
+
+// EOF
@@ -12,7 +12,6 @@ package asserts // import "tideland.dev/go/audit/asserts"
 //--------------------
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"path"
@@ -62,6 +61,18 @@ type FailureDetail interface {
 
 	// Message return the optional test message.
 	Message() string
+
+	// Obtained returns the obtained value passed to the failed test.
+	Obtained() any
+
+	// Expected returns the expected value passed to the failed test.
+	// In case of a range test it is a slice containing low and high.
+	Expected() any
+
+	// Diff returns the structural differences between obtained
+	// and expected value, one per line, if they could be computed.
+	// In case of error tests it contains the chain of wrapped errors.
+	Diff() []string
 }
 
 // failureDetail implements the FailureDetail interface.
@@ -72,6 +83,9 @@ type failureDetail struct {
 	test      Test
 	err       error
 	message   string
+	obtained  any
+	expected  any
+	diff      []string
 }
 
 // TImestamp implements the FailureDetail interface.
@@ -99,6 +113,21 @@ func (d *failureDetail) Message() string {
 	return d.message
 }
 
+// Obtained implements the FailureDetail interface.
+func (d *failureDetail) Obtained() any {
+	return d.obtained
+}
+
+// Expected implements the FailureDetail interface.
+func (d *failureDetail) Expected() any {
+	return d.expected
+}
+
+// Diff implements the FailureDetail interface.
+func (d *failureDetail) Diff() []string {
+	return d.diff
+}
+
 // Failures collects the collected failures
 // of a validation assertion.
 type Failures interface {
@@ -143,7 +172,8 @@ func (f *panicFailer) Logf(format string, args ...any) {
 
 // Fail implements the Failer interface.
 func (f panicFailer) Fail(test Test, obtained, expected any, msgs ...string) bool {
-	obex := obexString(test, obtained, expected)
+	diff := failureDiff(test, obtained, expected)
+	obex := obexString(test, obtained, expected, diff)
 	failStr := failString(test, obex, msgs...)
 	f.printer.Errorf(failStr)
 	panic(failStr)
@@ -152,7 +182,7 @@ func (f panicFailer) Fail(test Test, obtained, expected any, msgs ...string) boo
 // NewPanic creates a new Asserts instance which panics if a test fails.
 func NewPanic() *Asserts {
 	return New(&panicFailer{
-		printer: NewStandardPrinter(),
+		printer: defaultPrinter(NewStandardPrinter()),
 	})
 }
 
@@ -229,8 +259,15 @@ func (f *validationFailer) Logf(format string, args ...any) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	location, fun := here(f.offset)
-	prefix := fmt.Sprintf("%s %s(): ", location, fun)
-	f.printer.Logf(prefix+format+"\n", args...)
+	printLog(f.printer, location, fun, fmt.Sprintf(format, args...))
+}
+
+// snapshotTest implements snapshotTester.
+func (f *validationFailer) snapshotTest() (string, func(func())) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, fun := here(f.offset + 1)
+	return fun, nil
 }
 
 // Fail implements Failer.
@@ -238,18 +275,9 @@ func (f *validationFailer) Fail(test Test, obtained, expected any, msgs ...strin
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	location, fun := here(f.offset)
-	obex := obexString(test, obtained, expected)
-	err := errors.New(failString(test, obex, msgs...))
-	detail := &failureDetail{
-		timestamp: time.Now(),
-		location:  location,
-		fun:       fun,
-		test:      test,
-		err:       err,
-		message:   strings.Join(msgs, " "),
-	}
+	detail := newFailureDetail(location, fun, test, obtained, expected, msgs...)
 	f.details = append(f.details, detail)
-	f.errs = append(f.errs, err)
+	f.errs = append(f.errs, detail.err)
 	return false
 }
 
@@ -258,7 +286,7 @@ func (f *validationFailer) Fail(test Test, obtained, expected any, msgs ...strin
 // them.
 func NewValidation() (*Asserts, Failures) {
 	vf := &validationFailer{
-		printer: NewStandardPrinter(),
+		printer: defaultPrinter(NewStandardPrinter()),
 		offset:  4,
 		details: []FailureDetail{},
 		errs:    []error{},
@@ -266,6 +294,71 @@ func NewValidation() (*Asserts, Failures) {
 	return New(vf), vf
 }
 
+//--------------------
+// GROUP FAILER
+//--------------------
+
+// groupFailer collects the failures of a group of assertions
+// to report them together to its parent failer.
+type groupFailer struct {
+	mu      sync.Mutex
+	parent  Failer
+	offset  int
+	details []FailureDetail
+}
+
+// SetPrinter implements Failer.
+func (f *groupFailer) SetPrinter(printer Printer) Printer {
+	return f.parent.SetPrinter(printer)
+}
+
+// IncrCallstackOffset implements Failer.
+func (f *groupFailer) IncrCallstackOffset() func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	offset := f.offset
+	f.offset++
+	restoreParent := f.parent.IncrCallstackOffset()
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.offset = offset
+		restoreParent()
+	}
+}
+
+// Logf implements Failer.
+func (f *groupFailer) Logf(format string, args ...any) {
+	// Logging is done by the parent one level deeper.
+	restore := f.parent.IncrCallstackOffset()
+	defer restore()
+	f.parent.Logf(format, args...)
+}
+
+// snapshotTest implements snapshotTester.
+func (f *groupFailer) snapshotTest() (string, func(func())) {
+	restore := f.parent.IncrCallstackOffset()
+	defer restore()
+	return snapshotTest(f.parent)
+}
+
+// Fail implements Failer.
+func (f *groupFailer) Fail(test Test, obtained, expected any, msgs ...string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	location, fun := here(f.offset)
+	detail := newFailureDetail(location, fun, test, obtained, expected, msgs...)
+	f.details = append(f.details, detail)
+	return false
+}
+
+// collected returns the so far collected failure details.
+func (f *groupFailer) collected() []FailureDetail {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.details
+}
+
 //--------------------
 // TESTING FAILER
 //--------------------
@@ -324,8 +417,39 @@ func (f *testingFailer) Logf(format string, args ...any) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	location, fun := here(f.offset)
-	prefix := fmt.Sprintf("%s %s(): ", location, fun)
-	f.printer.Logf(prefix+format+"\n", args...)
+	printLog(f.printer, location, fun, fmt.Sprintf(format, args...))
+}
+
+// snapshotTest implements snapshotTester. The name is the one of
+// the test, the cleanup is done with the failable of the test.
+func (f *testingFailer) snapshotTest() (string, func(func())) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	failable := f.failable
+	name := ""
+	if namer, ok := failable.(interface{ Name() string }); ok {
+		name = namer.Name()
+	} else {
+		_, name = here(f.offset + 1)
+	}
+	cleaner, ok := failable.(interface{ Cleanup(func()) })
+	if !ok {
+		return name, nil
+	}
+	return name, func(cf func()) {
+		cleaner.Cleanup(func() {
+			f.mu.Lock()
+			current := f.failable
+			f.failable = failable
+			f.mu.Unlock()
+			defer func() {
+				f.mu.Lock()
+				f.failable = current
+				f.mu.Unlock()
+			}()
+			cf()
+		})
+	}
 }
 
 // Fail implements Failer.
@@ -333,45 +457,95 @@ func (f *testingFailer) Fail(test Test, obtained, expected any, msgs ...string)
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	location, fun := here(f.offset)
-	buffer := &bytes.Buffer{}
-
-	if test == Fail {
-		fmt.Fprintf(buffer, "%s assert in %s() failed {", location, fun)
-	} else {
-		fmt.Fprintf(buffer, "%s assert '%s' in %s() failed {", location, test, fun)
+	fl := &failure{
+		location: location,
+		fun:      fun,
+		test:     test,
+		obtained: obtained,
+		expected: expected,
+		message:  strings.Join(msgs, " "),
+		lines:    failureDiff(test, obtained, expected),
 	}
 	switch test {
-	case True, False, Nil, NotNil, NoError, Empty, NotEmpty, Panics:
-		fmt.Fprintf(buffer, "got: %v", obtained)
+	case True, False, Nil, NotNil, NoError, Empty, NotEmpty, Panics, Positive, Negative, NaN:
+		fl.add(obtainedRole, "got", "%v", obtained)
 	case Implementor, Assignable, Unassignable:
-		fmt.Fprintf(buffer, "got: %v, want: %v", ValueDescription(obtained), ValueDescription(expected))
+		fl.add(obtainedRole, "got", "%v", ValueDescription(obtained))
+		fl.add(expectedRole, "want", "%v", ValueDescription(expected))
 	case Contains, NotContains:
-		switch typedObtained := obtained.(type) {
-		case string:
-			fmt.Fprintf(buffer, "part: %s, full: %s", typedObtained, expected)
-		default:
-			fmt.Fprintf(buffer, "part: %v, full: %v", obtained, expected)
+		fl.add(obtainedRole, "part", "%v", obtained)
+		fl.add(expectedRole, "full", "%v", expected)
+	case Eventually, Consistently:
+		fl.add(obtainedRole, "got", "%v", obtained)
+		fl.add(expectedRole, "want", "%v", expected)
+	case ErrorIs, ErrorAs, PanicsWithError:
+		fl.add(obtainedRole, "got", "%v", obtained)
+		fl.add(expectedRole, "want", "%v", expected)
+		if len(fl.lines) > 0 {
+			fl.add(infoRole, "chain", "%d error(s)", len(fl.lines))
 		}
+	case Golden, Snapshot:
+		if len(fl.lines) > 0 {
+			fl.add(infoRole, "diff", "%d difference(s)", len(fl.lines))
+		} else {
+			fl.add(obtainedRole, "got", "%v", obtained)
+		}
+	case Sorted:
+		fl.add(obtainedRole, "got", "%v", obtained)
+		fl.add(expectedRole, "unsorted at", "%v", expected)
+	case NotPanics:
+		fl.add(obtainedRole, "panic", "%v", obtained)
+	case GoroutineLeak:
+		if pattern, ok := obtained.(string); ok {
+			fl.add(obtainedRole, "pattern", "%q", pattern)
+			fl.add(infoRole, "grace", "%v", expected)
+			break
+		}
+		stacks, _ := obtained.([]string)
+		fl.add(obtainedRole, "leaked", "%d goroutine(s)", len(stacks))
+		fl.add(infoRole, "grace", "%v", expected)
+	case HasPrefix:
+		fl.add(obtainedRole, "got", "%q", obtained)
+		fl.add(expectedRole, "prefix", "%q", expected)
+	case HasSuffix:
+		fl.add(obtainedRole, "got", "%q", obtained)
+		fl.add(expectedRole, "suffix", "%q", expected)
+	case TimeEqual, WithinDuration, Before, After, SameSecond, SameMinute, SameDay:
+		fl.add(obtainedRole, "got", "%s", timeString(obtained))
+		fl.add(expectedRole, "want", "%s", timeString(expected))
+	case Group:
+		details, _ := obtained.([]FailureDetail)
+		fl.add(infoRole, "group", "%v", expected)
+		fl.add(infoRole, "failures", "%d", len(details))
+		fl.lines = groupLines(details)
 	case Fail:
 	default:
-		fmt.Fprintf(buffer, "got: %v, want: %v", TypedValue(obtained), TypedValue(expected))
+		if format := testFormatter(test); format != nil {
+			fl.add(infoRole, "", "%s", format(obtained, expected))
+		} else if len(fl.lines) > 0 && isText(obtained, expected) {
+			fl.add(infoRole, "diff", "%d changed line(s)", changedLines(fl.lines))
+		} else if len(fl.lines) > 0 {
+			fl.add(infoRole, "diff", "%d difference(s)", len(fl.lines))
+			if ov, ok := expected.(*optionsValue); ok {
+				fl.add(infoRole, "options", "%v", ov.options)
+			}
+		} else {
+			fl.add(obtainedRole, "got", "%v", TypedValue(obtained))
+			fl.add(expectedRole, "want", "%v", TypedValue(expected))
+		}
 	}
 	if len(msgs) > 0 {
-		if buffer.Bytes()[buffer.Len()-1] != byte('{') {
-			fmt.Fprintf(buffer, ", ")
-		}
-		fmt.Fprintf(buffer, "info: %s", strings.Join(msgs, " "))
+		fl.add(infoRole, "info", "%s", fl.message)
 	}
-	fmt.Fprintf(buffer, "}\n")
 
 	switch f.mode {
 	case NoFailing:
-		f.printer.Logf(buffer.String())
+		printFailure(f.printer, fl, true)
 	case FailContinue:
-		f.printer.Errorf(buffer.String())
+		printFailure(f.printer, fl, false)
 		f.failable.Fail()
 	case FailStop:
-		f.printer.Errorf(buffer.String())
+		printFailure(f.printer, fl, false)
 		f.failable.FailNow()
 	}
 	return false
@@ -386,17 +560,166 @@ func NewTesting(f Failable, mode FailMode) *Asserts {
 		p = NewStandardPrinter()
 	}
 	return New(&testingFailer{
-		printer:  p,
+		printer:  defaultPrinter(p),
 		failable: f,
 		offset:   4,
 		mode:     mode,
 	})
 }
 
+//--------------------
+// FAILURE OUTPUT
+//--------------------
+
+// fieldRole tells if a field of a failure output contains
+// an obtained, an expected, or an informational value.
+type fieldRole int
+
+// Roles of the fields of a failure output.
+const (
+	infoRole fieldRole = iota
+	obtainedRole
+	expectedRole
+)
+
+// failureField is one named value of a failure output.
+type failureField struct {
+	role  fieldRole
+	name  string
+	value string
+}
+
+// failure contains the parts of the output of a failed assertion. Printers
+// implementing failurePrinter render it based on these parts, all others
+// print its plain text.
+type failure struct {
+	location string
+	fun      string
+	test     Test
+	obtained any
+	expected any
+	message  string
+	fields   []failureField
+	lines    []string
+}
+
+// add adds a formatted field to the failure.
+func (fl *failure) add(role fieldRole, name, format string, args ...any) {
+	fl.fields = append(fl.fields, failureField{
+		role:  role,
+		name:  name,
+		value: fmt.Sprintf(format, args...),
+	})
+}
+
+// header returns the first line of the failure without location
+// and fields.
+func (fl *failure) header() string {
+	if fl.test == Fail {
+		return fmt.Sprintf("assert in %s() failed", fl.fun)
+	}
+	return fmt.Sprintf("assert '%s' in %s() failed", fl.test, fl.fun)
+}
+
+// String returns the plain text of the failure.
+func (fl *failure) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s {", fl.location, fl.header())
+	for i, field := range fl.fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if field.name != "" {
+			b.WriteString(field.name + ": ")
+		}
+		b.WriteString(field.value)
+	}
+	b.WriteString("}\n")
+	for _, line := range fl.lines {
+		fmt.Fprintf(&b, "\t%s\n", line)
+	}
+	return b.String()
+}
+
+// failurePrinter is implemented by printers rendering the output
+// of the failers based on its parts instead of its plain text.
+type failurePrinter interface {
+	// printLog prints a logging of a failer at the location.
+	printLog(location, fun, text string)
+
+	// printFailure prints a failure, logged only if wanted.
+	printFailure(fl *failure, logged bool)
+}
+
+// printLog prints a logging of a failer with the location.
+func printLog(p Printer, location, fun, text string) {
+	if fp, ok := p.(failurePrinter); ok {
+		fp.printLog(location, fun, text)
+		return
+	}
+	p.Logf("%s %s(): %s\n", location, fun, text)
+}
+
+// printFailure prints a failure as error or logged only.
+func printFailure(p Printer, fl *failure, logged bool) {
+	if fp, ok := p.(failurePrinter); ok {
+		fp.printFailure(fl, logged)
+		return
+	}
+	if logged {
+		p.Logf("%s", fl.String())
+		return
+	}
+	p.Errorf("%s", fl.String())
+}
+
 //--------------------
 // HELPERS
 //--------------------
 
+// newFailureDetail creates the detail of a failure at the given location.
+func newFailureDetail(location, fun string, test Test, obtained, expected any, msgs ...string) *failureDetail {
+	diff := failureDiff(test, obtained, expected)
+	obex := obexString(test, obtained, expected, diff)
+	return &failureDetail{
+		timestamp: time.Now(),
+		location:  location,
+		fun:       fun,
+		test:      test,
+		err:       errors.New(failString(test, obex, msgs...)),
+		message:   strings.Join(msgs, " "),
+		obtained:  obtained,
+		expected:  unwrapExpected(expected),
+		diff:      diff,
+	}
+}
+
+// unwrapExpected returns the expected value of those tests
+// transporting it together with additional information.
+func unwrapExpected(expected any) any {
+	switch e := expected.(type) {
+	case *lowHigh:
+		return []any{e.low, e.high}
+	case *optionsValue:
+		return e.value
+	case *jsonPathValue:
+		return e.value
+	case *timeValue:
+		return e.time
+	}
+	return expected
+}
+
+// groupLines returns one line per failure detail of a group.
+func groupLines(details []FailureDetail) []string {
+	lines := []string{}
+	for _, detail := range details {
+		location, fun := detail.Location()
+		lines = append(lines, fmt.Sprintf("%s %s(): %v", location, fun, detail.Error()))
+	}
+	return lines
+}
+
 // here returns the location at the given offset.
 func here(offset int) (string, string) {
 	// Retrieve program counters.
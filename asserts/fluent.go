@@ -0,0 +1,413 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+//--------------------
+// VALUE
+//--------------------
+
+// Value allows fluent assertions about one obtained value. Each step
+// reports a failure like the according assertion of Asserts. After the
+// first failure the following steps of the chain are skipped.
+//
+//	assert.That(user.Name).IsNotEmpty().HasLength(5).Matches("^[A-Z]")
+//	assert.That(err).Is(ErrNotFound)
+type Value struct {
+	a        *Asserts
+	obtained any
+	failed   bool
+}
+
+// That starts fluent assertions about the obtained value.
+func (a *Asserts) That(obtained any) *Value {
+	return &Value{
+		a:        a,
+		obtained: obtained,
+	}
+}
+
+// Passed returns true if no step of the chain failed.
+func (v *Value) Passed() bool {
+	return !v.failed
+}
+
+// IsTrue tests if the value is true.
+func (v *Value) IsTrue(msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	b, err := boolValue(v.obtained)
+	if err != nil {
+		v.failed = !v.a.failer.Fail(True, v.obtained, true, appendMsg(msgs, "type missmatch: "+err.Error())...)
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.True(b, msgs...)
+	return v
+}
+
+// IsFalse tests if the value is false.
+func (v *Value) IsFalse(msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	b, err := boolValue(v.obtained)
+	if err != nil {
+		v.failed = !v.a.failer.Fail(False, v.obtained, false, appendMsg(msgs, "type missmatch: "+err.Error())...)
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.False(b, msgs...)
+	return v
+}
+
+// IsNil tests if the value is nil.
+func (v *Value) IsNil(msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.Nil(v.obtained, msgs...)
+	return v
+}
+
+// IsNotNil tests if the value is not nil.
+func (v *Value) IsNotNil(msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.NotNil(v.obtained, msgs...)
+	return v
+}
+
+// IsZero tests if the value is the zero value of its type.
+func (v *Value) IsZero(msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.Zero(v.obtained, msgs...)
+	return v
+}
+
+// IsEmpty tests if the value is empty.
+func (v *Value) IsEmpty(msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.Empty(v.obtained, msgs...)
+	return v
+}
+
+// IsNotEmpty tests if the value is not empty.
+func (v *Value) IsNotEmpty(msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.NotEmpty(v.obtained, msgs...)
+	return v
+}
+
+// Equals tests if the value is equal to the expected one.
+func (v *Value) Equals(expected any, msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.Equal(v.obtained, expected, msgs...)
+	return v
+}
+
+// IsDifferent tests if the value is different from the expected one.
+func (v *Value) IsDifferent(expected any, msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.Different(v.obtained, expected, msgs...)
+	return v
+}
+
+// HasLength tests if the value has the expected length.
+func (v *Value) HasLength(expected int, msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.Length(v.obtained, expected, msgs...)
+	return v
+}
+
+// Contains tests if the value, a string, array, or slice, contains the part.
+func (v *Value) Contains(part any, msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.Contains(part, v.obtained, msgs...)
+	return v
+}
+
+// Matches tests if the value, a string or formatted as one, contains a
+// match of the regular expression. Other than Asserts.Match() the regular
+// expression is not anchored, so failures are reported as test
+// ContainsMatch instead of Match.
+func (v *Value) Matches(regex string, msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	obtained, ok := v.obtained.(string)
+	if !ok {
+		obtained = fmt.Sprintf("%v", v.obtained)
+	}
+	matches, err := regexp.MatchString(regex, obtained)
+	if err != nil {
+		v.failed = !v.a.failer.Fail(ContainsMatch, obtained, regex, appendMsg(msgs, "can't compile regex: "+err.Error())...)
+		return v
+	}
+	if !matches {
+		v.failed = !v.a.failer.Fail(ContainsMatch, obtained, regex, msgs...)
+	}
+	return v
+}
+
+// IsNoError tests if the value is no error.
+func (v *Value) IsNoError(msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.NoError(v.obtained, msgs...)
+	return v
+}
+
+// Is tests if the value is an error matching the target
+// in its chain, see Asserts.ErrorIs().
+func (v *Value) Is(target error, msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.ErrorIs(v.obtained, target, msgs...)
+	return v
+}
+
+// As tests if the value is an error with an error in its chain
+// assignable to the target, see Asserts.ErrorAs().
+func (v *Value) As(target any, msgs ...string) *Value {
+	if v.failed {
+		return v
+	}
+	restore := v.a.IncrCallstackOffset()
+	defer restore()
+	v.failed = !v.a.ErrorAs(v.obtained, target, msgs...)
+	return v
+}
+
+//--------------------
+// SLICE VALUE
+//--------------------
+
+// SliceValue allows fluent assertions about an obtained slice or array.
+// Like Value it skips the following steps after the first failure.
+//
+//	assert.ThatSlice(xs).ContainsAll(1, 2).IsSorted()
+type SliceValue struct {
+	a        *Asserts
+	obtained any
+	failed   bool
+}
+
+// ThatSlice starts fluent assertions about the obtained slice or array.
+func (a *Asserts) ThatSlice(obtained any) *SliceValue {
+	return &SliceValue{
+		a:        a,
+		obtained: obtained,
+	}
+}
+
+// Passed returns true if no step of the chain failed.
+func (sv *SliceValue) Passed() bool {
+	return !sv.failed
+}
+
+// IsEmpty tests if the slice is empty.
+func (sv *SliceValue) IsEmpty(msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.Empty(sv.obtained, msgs...)
+	return sv
+}
+
+// IsNotEmpty tests if the slice is not empty.
+func (sv *SliceValue) IsNotEmpty(msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.NotEmpty(sv.obtained, msgs...)
+	return sv
+}
+
+// HasLength tests if the slice has the expected length.
+func (sv *SliceValue) HasLength(expected int, msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.Length(sv.obtained, expected, msgs...)
+	return sv
+}
+
+// Equals tests if the slice is equal to the expected one.
+func (sv *SliceValue) Equals(expected any, msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.Equal(sv.obtained, expected, msgs...)
+	return sv
+}
+
+// Contains tests if the slice contains the element.
+func (sv *SliceValue) Contains(element any, msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.Contains(element, sv.obtained, msgs...)
+	return sv
+}
+
+// NotContains tests if the slice does not contain the element.
+func (sv *SliceValue) NotContains(element any, msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.NotContains(element, sv.obtained, msgs...)
+	return sv
+}
+
+// ContainsAll tests if the slice contains all the elements.
+func (sv *SliceValue) ContainsAll(elements ...any) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.ContainsAll(sv.obtained, elements)
+	return sv
+}
+
+// ContainsAny tests if the slice contains at least one of the elements.
+func (sv *SliceValue) ContainsAny(elements ...any) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.ContainsAny(sv.obtained, elements)
+	return sv
+}
+
+// ElementsMatch tests if the slice contains the same elements
+// as the expected one regardless of their order.
+func (sv *SliceValue) ElementsMatch(expected any, msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.ElementsMatch(sv.obtained, expected, msgs...)
+	return sv
+}
+
+// IsSubsetOf tests if all elements of the slice are contained
+// in the full one.
+func (sv *SliceValue) IsSubsetOf(full any, msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.Subset(sv.obtained, full, msgs...)
+	return sv
+}
+
+// IsSorted tests if the elements of the slice, numbers or strings,
+// are sorted in ascending order.
+func (sv *SliceValue) IsSorted(msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.Sorted(sv.obtained, msgs...)
+	return sv
+}
+
+// IsUnique tests if the slice contains no duplicate elements.
+func (sv *SliceValue) IsUnique(msgs ...string) *SliceValue {
+	if sv.failed {
+		return sv
+	}
+	restore := sv.a.IncrCallstackOffset()
+	defer restore()
+	sv.failed = !sv.a.Unique(sv.obtained, msgs...)
+	return sv
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// boolValue returns the value as bool, also for named bool types.
+func boolValue(obtained any) (bool, error) {
+	rv := reflect.ValueOf(obtained)
+	if rv.Kind() != reflect.Bool {
+		return false, fmt.Errorf("obtained %s is no bool", ValueDescription(obtained))
+	}
+	return rv.Bool(), nil
+}
+
+// EOF
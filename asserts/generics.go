@@ -0,0 +1,144 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// CONSTRAINTS
+//--------------------
+
+// Ordered describes all types supporting the operators <, <=, >=, and >.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~string
+}
+
+// Float describes all floating-point types.
+type Float interface {
+	~float32 | ~float64
+}
+
+//--------------------
+// GENERIC ASSERTS
+//--------------------
+
+// The generic assertions work like their counterparts of Asserts but
+// let the compiler check the types of obtained and expected values.
+// As methods cannot have type parameters they are functions taking
+// the Asserts instance as first argument.
+//
+//	asserts.EqualT(assert, obtained, int64(4711), "has to be 4711")
+//	asserts.RangeT(assert, uint32(5), 1, 10)
+//	asserts.KeyT(assert, m, "foo")
+
+// EqualT tests if obtained and expected are equal.
+func EqualT[T comparable](a *Asserts, obtained, expected T, msgs ...string) bool {
+	if obtained != expected {
+		return a.failer.Fail(Equal, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// DifferentT tests if obtained and expected are different.
+func DifferentT[T comparable](a *Asserts, obtained, expected T, msgs ...string) bool {
+	if obtained == expected {
+		return a.failer.Fail(Different, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// ZeroT tests if obtained is the zero value of its type.
+func ZeroT[T comparable](a *Asserts, obtained T, msgs ...string) bool {
+	var zero T
+	if obtained != zero {
+		return a.failer.Fail(Zero, obtained, zero, msgs...)
+	}
+	return true
+}
+
+// ContainsT tests if the part is an element of the full slice.
+func ContainsT[S ~[]E, E comparable](a *Asserts, part E, full S, msgs ...string) bool {
+	if !containsElement(part, full) {
+		return a.failer.Fail(Contains, part, full, msgs...)
+	}
+	return true
+}
+
+// NotContainsT tests if the part is no element of the full slice.
+func NotContainsT[S ~[]E, E comparable](a *Asserts, part E, full S, msgs ...string) bool {
+	if containsElement(part, full) {
+		return a.failer.Fail(NotContains, part, full, msgs...)
+	}
+	return true
+}
+
+// RangeT tests if obtained is larger or equal low and lower or
+// equal high.
+func RangeT[T Ordered](a *Asserts, obtained, low, high T, msgs ...string) bool {
+	if obtained < low || obtained > high {
+		return a.failer.Fail(Range, obtained, &lowHigh{low, high}, msgs...)
+	}
+	return true
+}
+
+// AboutT tests if obtained and expected are near to each other
+// (within the given extent).
+func AboutT[T Float](a *Asserts, obtained, expected, extent T, msgs ...string) bool {
+	if extent < 0 {
+		extent = -extent
+	}
+	if obtained < expected-extent || obtained > expected+extent {
+		return a.failer.Fail(About, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// LengthT tests if the len of the obtained slice is equal to the
+// expected one.
+func LengthT[S ~[]E, E any](a *Asserts, obtained S, expected int, msgs ...string) bool {
+	if len(obtained) != expected {
+		return a.failer.Fail(Length, len(obtained), expected, msgs...)
+	}
+	return true
+}
+
+// KeyT tests if the obtained map contains the expected key.
+func KeyT[M ~map[K]V, K comparable, V any](a *Asserts, obtained M, key K, msgs ...string) bool {
+	if _, ok := obtained[key]; !ok {
+		return a.failer.Fail(HasKey, obtained, key, msgs...)
+	}
+	return true
+}
+
+// SortedByT tests if the elements of the obtained slice are sorted
+// in ascending order of the keys returned by the key function.
+func SortedByT[S ~[]E, E any, K Ordered](a *Asserts, obtained S, key func(E) K, msgs ...string) bool {
+	for i := 1; i < len(obtained); i++ {
+		if key(obtained[i]) < key(obtained[i-1]) {
+			return a.failer.Fail(Sorted, obtained, i, msgs...)
+		}
+	}
+	return true
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// containsElement checks if part is an element of full.
+func containsElement[S ~[]E, E comparable](part E, full S) bool {
+	for _, e := range full {
+		if e == part {
+			return true
+		}
+	}
+	return false
+}
+
+// EOF
@@ -0,0 +1,96 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+//--------------------
+// CONSTANTS
+//--------------------
+
+// UpdateEnv names the environment variable which lets golden files
+// be rewritten instead of compared when set to true.
+const UpdateEnv = "AUDIT_UPDATE"
+
+// goldenDir is the directory containing the golden files.
+const goldenDir = "testdata"
+
+//--------------------
+// GOLDEN
+//--------------------
+
+// Golden compares the obtained string or byte slice with the content of the
+// golden file testdata/<name>.golden. In update mode the file is written
+// instead. This mode is activated by setting the environment variable
+// AUDIT_UPDATE to true or by an own test flag -update, e.g.
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+func (a *Asserts) Golden(name string, obtained any, msgs ...string) bool {
+	var content string
+	switch o := obtained.(type) {
+	case string:
+		content = o
+	case []byte:
+		content = string(o)
+	default:
+		content = fmt.Sprintf("%v", o)
+	}
+	filename := filepath.Join(goldenDir, name+".golden")
+	if isUpdateMode() {
+		if err := writeGolden(filename, content); err != nil {
+			return a.failer.Fail(Golden, filename, nil, appendMsg(msgs, "cannot write golden file: "+err.Error())...)
+		}
+		a.failer.Logf("golden file %q updated", filename)
+		return true
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return a.failer.Fail(Golden, filename, nil, appendMsg(msgs, "cannot read golden file: "+err.Error())...)
+	}
+	expected := string(data)
+	if content != expected {
+		return a.failer.Fail(Golden, content, expected, msgs...)
+	}
+	return true
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// isUpdateMode checks if golden files shall be updated.
+func isUpdateMode() bool {
+	if update, err := strconv.ParseBool(os.Getenv(UpdateEnv)); err == nil && update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		update, err := strconv.ParseBool(f.Value.String())
+		return err == nil && update
+	}
+	return false
+}
+
+// writeGolden writes the content to the golden file.
+func writeGolden(filename, content string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, []byte(content), 0644)
+}
+
+// EOF
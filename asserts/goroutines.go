@@ -0,0 +1,182 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+//--------------------
+// GOROUTINES
+//--------------------
+
+// goroutineRetryPause is the pause between the checks for leaked
+// goroutines during the grace period.
+const goroutineRetryPause = 10 * time.Millisecond
+
+// DefaultGoroutineIgnores contains the patterns of goroutines running
+// in the background of tests, e.g. for the signal handling or tests
+// waiting for their parallel execution. They are always ignored.
+var DefaultGoroutineIgnores = []string{
+	`os/signal\.signal_recv`,
+	`os/signal\.loop`,
+	`runtime\.ensureSigM`,
+	`testing\.\(\*T\)\.Parallel`,
+}
+
+// GoroutineSnapshot contains the goroutines running at the time
+// of its creation.
+type GoroutineSnapshot struct {
+	stacks map[string]string
+}
+
+// NewGoroutineSnapshot records the currently running goroutines.
+// A later Asserts.NoNewGoroutines() tests if the goroutines started
+// since then have ended.
+func NewGoroutineSnapshot() *GoroutineSnapshot {
+	return &GoroutineSnapshot{
+		stacks: goroutineStacks(),
+	}
+}
+
+// NoGoroutineLeak runs the function and tests if all goroutines
+// started by it end at latest after the grace period. Goroutines
+// with stacks matching one of the regular expressions to ignore
+// or of DefaultGoroutineIgnores are not reported as leaked. The
+// patterns are checked before the function is run.
+func (a *Asserts) NoGoroutineLeak(f func(), grace time.Duration, ignores []string, msgs ...string) bool {
+	res, pattern, err := compileGoroutineIgnores(ignores)
+	if err != nil {
+		return a.failer.Fail(GoroutineLeak, pattern, grace, appendMsg(msgs, "can't compile regex: "+err.Error())...)
+	}
+	snapshot := NewGoroutineSnapshot()
+	f()
+	leaked := awaitGoroutines(snapshot, grace, res)
+	if len(leaked) > 0 {
+		return a.failer.Fail(GoroutineLeak, leaked, grace, msgs...)
+	}
+	return true
+}
+
+// NoNewGoroutines tests if all goroutines started since the snapshot
+// end at latest after the grace period. Goroutines with stacks matching
+// one of the regular expressions to ignore or of DefaultGoroutineIgnores
+// are not reported as leaked.
+func (a *Asserts) NoNewGoroutines(snapshot *GoroutineSnapshot, grace time.Duration, ignores []string, msgs ...string) bool {
+	res, pattern, err := compileGoroutineIgnores(ignores)
+	if err != nil {
+		return a.failer.Fail(GoroutineLeak, pattern, grace, appendMsg(msgs, "can't compile regex: "+err.Error())...)
+	}
+	leaked := awaitGoroutines(snapshot, grace, res)
+	if len(leaked) > 0 {
+		return a.failer.Fail(GoroutineLeak, leaked, grace, msgs...)
+	}
+	return true
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// compileGoroutineIgnores compiles the patterns to ignore together with
+// DefaultGoroutineIgnores. In case of an error the invalid pattern is
+// returned too.
+func compileGoroutineIgnores(ignores []string) ([]*regexp.Regexp, string, error) {
+	res := []*regexp.Regexp{}
+	patterns := append(append([]string{}, DefaultGoroutineIgnores...), ignores...)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, pattern, err
+		}
+		res = append(res, re)
+	}
+	return res, "", nil
+}
+
+// awaitGoroutines waits until the goroutines started since the snapshot
+// have ended or the grace period is over. It returns the stacks of those
+// still running.
+func awaitGoroutines(snapshot *GoroutineSnapshot, grace time.Duration, ignores []*regexp.Regexp) []string {
+	deadline := time.Now().Add(grace)
+	for {
+		leaked := leakedGoroutines(snapshot, ignores)
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(goroutineRetryPause)
+	}
+}
+
+// goroutineStacks returns the stacks of all running goroutines
+// mapped by their header line containing the ID.
+func goroutineStacks() map[string]string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := map[string]string{}
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		stack = strings.TrimSpace(stack)
+		if stack == "" {
+			continue
+		}
+		stacks[goroutineID(stack)] = stack
+	}
+	return stacks
+}
+
+// goroutineID returns the ID part of the stack header
+// "goroutine 42 [chan receive]:".
+func goroutineID(stack string) string {
+	header, _, _ := strings.Cut(stack, "\n")
+	id, _, _ := strings.Cut(header, " [")
+	return id
+}
+
+// leakedGoroutines returns the sorted stacks of the goroutines not contained
+// in the snapshot and not matching one of the patterns to ignore.
+func leakedGoroutines(snapshot *GoroutineSnapshot, ignores []*regexp.Regexp) []string {
+	leaked := []string{}
+	for id, stack := range goroutineStacks() {
+		if _, ok := snapshot.stacks[id]; ok {
+			continue
+		}
+		if isIgnoredGoroutine(stack, ignores) {
+			continue
+		}
+		leaked = append(leaked, stack)
+	}
+	sort.Strings(leaked)
+	return leaked
+}
+
+// isIgnoredGoroutine checks if the stack matches one of the patterns.
+func isIgnoredGoroutine(stack string, ignores []*regexp.Regexp) bool {
+	for _, re := range ignores {
+		if re.MatchString(stack) {
+			return true
+		}
+	}
+	return false
+}
+
+// EOF
@@ -0,0 +1,286 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//--------------------
+// JSON
+//--------------------
+
+// JSONEqual tests if the obtained and expected JSON documents are
+// semantically equal. Whitespace and the order of object keys do not
+// matter. Both can be passed as string, byte slice, or json.RawMessage,
+// e.g. the result of web.BodyToString().
+func (a *Asserts) JSONEqual(obtained, expected any, msgs ...string) bool {
+	od, err := jsonDocument(obtained)
+	if err != nil {
+		return a.failer.Fail(JSONEqual, obtained, expected, appendMsg(msgs, "invalid obtained JSON: "+err.Error())...)
+	}
+	ed, err := jsonDocument(expected)
+	if err != nil {
+		return a.failer.Fail(JSONEqual, obtained, expected, appendMsg(msgs, "invalid expected JSON: "+err.Error())...)
+	}
+	if len(jsonDiff("$", od, ed)) > 0 {
+		return a.failer.Fail(JSONEqual, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// JSONPath tests if the value at the path inside the obtained JSON document
+// is equal to the expected value. The path supports object keys and array
+// indices like "$.items[0].id" or `$["a key"][1]`. The expected value can
+// be any Go value, it is compared with its JSON representation.
+func (a *Asserts) JSONPath(obtained any, path string, expected any, msgs ...string) bool {
+	od, err := jsonDocument(obtained)
+	if err != nil {
+		return a.failer.Fail(JSONPath, obtained, path, appendMsg(msgs, "invalid obtained JSON: "+err.Error())...)
+	}
+	value, err := jsonLookup(od, path)
+	if err != nil {
+		return a.failer.Fail(JSONPath, obtained, path, appendMsg(msgs, err.Error())...)
+	}
+	ev, err := jsonNormalize(expected)
+	if err != nil {
+		return a.failer.Fail(JSONPath, obtained, path, appendMsg(msgs, "invalid expected value: "+err.Error())...)
+	}
+	if len(jsonDiff(path, value, ev)) > 0 {
+		return a.failer.Fail(JSONPath, value, &jsonPathValue{path, ev}, msgs...)
+	}
+	return true
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// jsonPathValue transports the expected value of a JSON path test
+// together with its path.
+type jsonPathValue struct {
+	path  string
+	value any
+}
+
+// String implements fmt.Stringer.
+func (jpv *jsonPathValue) String() string {
+	return fmt.Sprintf("%s = %s", jpv.path, jsonString(jpv.value))
+}
+
+// jsonDocument parses a JSON document passed as string or bytes.
+func jsonDocument(doc any) (any, error) {
+	var data []byte
+	switch d := doc.(type) {
+	case string:
+		data = []byte(d)
+	case []byte:
+		data = d
+	case json.RawMessage:
+		data = d
+	default:
+		return nil, fmt.Errorf("document is %s and no string or bytes", ValueDescription(doc))
+	}
+	return jsonDecode(data)
+}
+
+// jsonNormalize converts a Go value into its generic JSON representation.
+func jsonNormalize(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return jsonDecode(data)
+}
+
+// jsonDecode decodes JSON data into its generic representation. Numbers
+// are kept as json.Number so that large integers don't lose precision.
+func jsonDecode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level value")
+	}
+	return decoded, nil
+}
+
+// jsonLookup returns the value at the given path of the document.
+func jsonLookup(doc any, path string) (any, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path %q does not start with '$'", path)
+	}
+	current := doc
+	rest := path[1:]
+	for rest != "" {
+		var key string
+		index := -1
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key = rest[1 : end+1]
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has unclosed bracket", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if strings.HasPrefix(inner, `"`) {
+				unquoted, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("path %q has invalid key %s", path, inner)
+				}
+				key = unquoted
+			} else {
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("path %q has invalid index %s", path, inner)
+				}
+				index = i
+			}
+		default:
+			return nil, fmt.Errorf("path %q is invalid at %q", path, rest)
+		}
+		if index >= 0 {
+			array, ok := current.([]any)
+			if !ok || index >= len(array) {
+				return nil, fmt.Errorf("path %q not found: no array element %d", path, index)
+			}
+			current = array[index]
+			continue
+		}
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("path %q not found: no object for key %q", path, key)
+		}
+		value, ok := object[key]
+		if !ok {
+			return nil, fmt.Errorf("path %q not found: no key %q", path, key)
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// jsonDiff compares two generic JSON values and returns one line
+// per difference prefixed by its JSON path.
+func jsonDiff(path string, obtained, expected any) []string {
+	diffs := []string{}
+	switch ev := expected.(type) {
+	case map[string]any:
+		ov, ok := obtained.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := []string{}
+		for key := range ov {
+			keys = append(keys, key)
+		}
+		for key := range ev {
+			if _, ok := ov[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := jsonPathKey(path, key)
+			ovv, ook := ov[key]
+			evv, eok := ev[key]
+			switch {
+			case !ook:
+				diffs = append(diffs, fmt.Sprintf("%s: got <missing>, want %s", keyPath, jsonString(evv)))
+			case !eok:
+				diffs = append(diffs, fmt.Sprintf("%s: got %s, want <missing>", keyPath, jsonString(ovv)))
+			default:
+				diffs = append(diffs, jsonDiff(keyPath, ovv, evv)...)
+			}
+		}
+		return diffs
+	case []any:
+		ov, ok := obtained.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(ov) || i < len(ev); i++ {
+			indexPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(ov):
+				diffs = append(diffs, fmt.Sprintf("%s: got <missing>, want %s", indexPath, jsonString(ev[i])))
+			case i >= len(ev):
+				diffs = append(diffs, fmt.Sprintf("%s: got %s, want <missing>", indexPath, jsonString(ov[i])))
+			default:
+				diffs = append(diffs, jsonDiff(indexPath, ov[i], ev[i])...)
+			}
+		}
+		return diffs
+	}
+	if on, ok := obtained.(json.Number); ok {
+		if en, ok := expected.(json.Number); ok && jsonNumberEqual(on, en) {
+			return diffs
+		}
+	}
+	if !reflect.DeepEqual(obtained, expected) {
+		diffs = append(diffs, fmt.Sprintf("%s: got %s, want %s", path, jsonString(obtained), jsonString(expected)))
+	}
+	return diffs
+}
+
+// jsonNumberEqual compares two JSON numbers exactly by their values,
+// so that e.g. 1 and 1.0 are equal.
+func jsonNumberEqual(obtained, expected json.Number) bool {
+	or, ook := new(big.Rat).SetString(obtained.String())
+	er, eok := new(big.Rat).SetString(expected.String())
+	if !ook || !eok {
+		return obtained == expected
+	}
+	return or.Cmp(er) == 0
+}
+
+// jsonPathKey appends a key to a JSON path.
+func jsonPathKey(path, key string) string {
+	if key == "" {
+		return path + `[""]`
+	}
+	for _, r := range key {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return path + "[" + strconv.Quote(key) + "]"
+		}
+	}
+	return path + "." + key
+}
+
+// jsonString returns the compact JSON representation of a value.
+func jsonString(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// EOF
@@ -0,0 +1,383 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+//--------------------
+// NUMERIC
+//--------------------
+
+// AboutRel tests if the numbers of any kind obtained and expected are
+// near to each other relative to the size of expected, e.g. 0.01 for 1%.
+// Large integers are compared without losing precision.
+func (a *Asserts) AboutRel(obtained, expected any, rel float64, msgs ...string) bool {
+	about, err := isAboutRel(obtained, expected, rel)
+	if err != nil {
+		return a.failer.Fail(AboutRel, obtained, expected, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if !about {
+		return a.failer.Fail(AboutRel, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// AboutULP tests if the floating-point numbers obtained and expected,
+// both float32 or both float64, are at most the given units in the
+// last place (ULP) apart. So it is independent of their size.
+func (a *Asserts) AboutULP(obtained, expected any, ulps uint64, msgs ...string) bool {
+	distance, err := ulpDistance(obtained, expected)
+	if err != nil {
+		return a.failer.Fail(AboutULP, obtained, expected, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if distance > ulps {
+		return a.failer.Fail(AboutULP, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// Positive tests if the obtained number of any kind is larger than zero.
+func (a *Asserts) Positive(obtained any, msgs ...string) bool {
+	sign, err := numberSign(obtained)
+	if err != nil {
+		return a.failer.Fail(Positive, obtained, nil, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if sign <= 0 {
+		return a.failer.Fail(Positive, obtained, nil, msgs...)
+	}
+	return true
+}
+
+// Negative tests if the obtained number of any kind is less than zero.
+func (a *Asserts) Negative(obtained any, msgs ...string) bool {
+	sign, err := numberSign(obtained)
+	if err != nil {
+		return a.failer.Fail(Negative, obtained, nil, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if sign >= 0 {
+		return a.failer.Fail(Negative, obtained, nil, msgs...)
+	}
+	return true
+}
+
+// NaN tests if the obtained floating-point number is not a number.
+func (a *Asserts) NaN(obtained any, msgs ...string) bool {
+	f, err := floatOf(obtained)
+	if err != nil {
+		return a.failer.Fail(NaN, obtained, nil, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if !math.IsNaN(f) {
+		return a.failer.Fail(NaN, obtained, nil, msgs...)
+	}
+	return true
+}
+
+// Inf tests if the obtained floating-point number is infinite. A positive
+// sign tests for positive infinity, a negative one for negative infinity,
+// and zero for both.
+func (a *Asserts) Inf(obtained any, sign int, msgs ...string) bool {
+	f, err := floatOf(obtained)
+	if err != nil {
+		return a.failer.Fail(Inf, obtained, sign, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if !math.IsInf(f, sign) {
+		return a.failer.Fail(Inf, obtained, sign, msgs...)
+	}
+	return true
+}
+
+// Increasing tests if the numbers of the obtained array or slice are
+// increasing. If strict is true equal neighbors are not allowed.
+func (a *Asserts) Increasing(obtained any, strict bool, msgs ...string) bool {
+	index, err := nonMonotonicIndex(obtained, 1, strict)
+	if err != nil {
+		return a.failer.Fail(Increasing, obtained, nil, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if index >= 0 {
+		return a.failer.Fail(Increasing, obtained, index, msgs...)
+	}
+	return true
+}
+
+// Decreasing tests if the numbers of the obtained array or slice are
+// decreasing. If strict is true equal neighbors are not allowed.
+func (a *Asserts) Decreasing(obtained any, strict bool, msgs ...string) bool {
+	index, err := nonMonotonicIndex(obtained, -1, strict)
+	if err != nil {
+		return a.failer.Fail(Decreasing, obtained, nil, appendMsg(msgs, "type missmatch: "+err.Error())...)
+	}
+	if index >= 0 {
+		return a.failer.Fail(Decreasing, obtained, index, msgs...)
+	}
+	return true
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// isNumber checks if the value is of an integer or floating-point kind.
+func isNumber(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isSigned checks if the value is of a signed integer kind.
+func isSigned(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// isFloat checks if the value is of a floating-point kind.
+func isFloat(value reflect.Value) bool {
+	return value.Kind() == reflect.Float32 || value.Kind() == reflect.Float64
+}
+
+// compareNumbers compares two numbers of any kinds without losing the
+// precision of large integers. It returns -1, 0, or 1 and false if
+// they are not comparable, e.g. because one is NaN.
+func compareNumbers(x, y reflect.Value) (int, bool) {
+	if !isNumber(x) || !isNumber(y) {
+		return 0, false
+	}
+	switch {
+	case isFloat(x) && !isFloat(y):
+		cmp, ok := compareIntFloat(y, x.Float())
+		return -cmp, ok
+	case isFloat(y) && !isFloat(x):
+		return compareIntFloat(x, y.Float())
+	case isFloat(x):
+		xf, yf := x.Float(), y.Float()
+		switch {
+		case xf < yf:
+			return -1, true
+		case xf > yf:
+			return 1, true
+		case xf == yf:
+			return 0, true
+		}
+		return 0, false
+	case isSigned(x) && isSigned(y):
+		return compareInts(x.Int(), y.Int()), true
+	case isSigned(x):
+		if x.Int() < 0 {
+			return -1, true
+		}
+		return compareUints(uint64(x.Int()), y.Uint()), true
+	case isSigned(y):
+		if y.Int() < 0 {
+			return 1, true
+		}
+		return compareUints(x.Uint(), uint64(y.Int())), true
+	}
+	return compareUints(x.Uint(), y.Uint()), true
+}
+
+// compareInts compares two signed integers.
+func compareInts(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// compareUints compares two unsigned integers.
+func compareUints(x, y uint64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// compareIntFloat compares a signed or unsigned integer with a float.
+// Instead of widening the integer to float64, which loses precision
+// above 2^53, the integral part of the float is compared as integer.
+func compareIntFloat(x reflect.Value, yf float64) (int, bool) {
+	if math.IsNaN(yf) {
+		return 0, false
+	}
+	yt := math.Trunc(yf)
+	var cmp int
+	if isSigned(x) {
+		switch {
+		case yt >= 1<<63:
+			return -1, true
+		case yt < -(1 << 63):
+			return 1, true
+		}
+		cmp = compareInts(x.Int(), int64(yt))
+	} else {
+		switch {
+		case yt >= 1<<64:
+			return -1, true
+		case yt < 0:
+			return 1, true
+		}
+		cmp = compareUints(x.Uint(), uint64(yt))
+	}
+	if cmp != 0 {
+		return cmp, true
+	}
+	// Same integral part, so the fraction decides.
+	switch {
+	case yf > yt:
+		return -1, true
+	case yf < yt:
+		return 1, true
+	}
+	return 0, true
+}
+
+// floatOf returns the obtained floating-point number as float64.
+func floatOf(obtained any) (float64, error) {
+	value := reflect.ValueOf(obtained)
+	if !isFloat(value) {
+		return 0, fmt.Errorf("obtained %s is no floating-point number", ValueDescription(obtained))
+	}
+	return value.Float(), nil
+}
+
+// numberSign returns the sign of the obtained number of any kind.
+func numberSign(obtained any) (int, error) {
+	value := reflect.ValueOf(obtained)
+	if !isNumber(value) {
+		return 0, fmt.Errorf("obtained %s is no number", ValueDescription(obtained))
+	}
+	sign, ok := compareNumbers(value, reflect.ValueOf(0))
+	if !ok {
+		// NaN has no sign.
+		return 0, nil
+	}
+	return sign, nil
+}
+
+// isAboutRel checks if obtained and expected are near to each other
+// relative to the size of expected. The difference is computed exactly,
+// so integers above 2^53 don't lose their precision.
+func isAboutRel(obtained, expected any, rel float64) (bool, error) {
+	ov := reflect.ValueOf(obtained)
+	ev := reflect.ValueOf(expected)
+	if !isNumber(ov) || !isNumber(ev) {
+		return false, fmt.Errorf("obtained %s and expected %s are no numbers",
+			ValueDescription(obtained), ValueDescription(expected))
+	}
+	cmp, ok := compareNumbers(ov, ev)
+	switch {
+	case !ok || math.IsNaN(rel):
+		// NaN is never about anything.
+		return false, nil
+	case cmp == 0 || math.IsInf(rel, 0):
+		return true, nil
+	case isFloat(ov) && math.IsInf(ov.Float(), 0), isFloat(ev) && math.IsInf(ev.Float(), 0):
+		return false, nil
+	}
+	ob, eb := bigNumber(ov), bigNumber(ev)
+	distance := new(big.Float).Sub(ob, eb)
+	limit := new(big.Float).Mul(eb, big.NewFloat(rel))
+	return distance.Abs(distance).Cmp(limit.Abs(limit)) <= 0, nil
+}
+
+// bigNumber returns a finite number of any kind as exact big.Float.
+func bigNumber(value reflect.Value) *big.Float {
+	switch {
+	case isFloat(value):
+		return new(big.Float).SetFloat64(value.Float())
+	case isSigned(value):
+		return new(big.Float).SetInt64(value.Int())
+	}
+	return new(big.Float).SetUint64(value.Uint())
+}
+
+// ulpDistance returns the number of units in the last place between
+// two floating-point numbers of the same kind.
+func ulpDistance(obtained, expected any) (uint64, error) {
+	ov := reflect.ValueOf(obtained)
+	ev := reflect.ValueOf(expected)
+	if !isFloat(ov) || ov.Kind() != ev.Kind() {
+		return 0, errors.New("obtained and expected are no floating-point numbers of the same kind")
+	}
+	of, ef := ov.Float(), ev.Float()
+	switch {
+	case math.IsNaN(of) || math.IsNaN(ef):
+		return math.MaxUint64, nil
+	case of == ef:
+		return 0, nil
+	}
+	var oi, ei int64
+	if ov.Kind() == reflect.Float32 {
+		oi = int64(int32(math.Float32bits(float32(of))))
+		ei = int64(int32(math.Float32bits(float32(ef))))
+		oi, ei = orderedBits(oi, math.MinInt32), orderedBits(ei, math.MinInt32)
+	} else {
+		oi = int64(math.Float64bits(of))
+		ei = int64(math.Float64bits(ef))
+		oi, ei = orderedBits(oi, math.MinInt64), orderedBits(ei, math.MinInt64)
+	}
+	if oi > ei {
+		return uint64(oi) - uint64(ei), nil
+	}
+	return uint64(ei) - uint64(oi), nil
+}
+
+// orderedBits maps the bits of a negative floating-point number so that
+// the integers are ordered like the numbers.
+func orderedBits(bits, min int64) int64 {
+	if bits < 0 {
+		return min - bits
+	}
+	return bits
+}
+
+// nonMonotonicIndex returns the index of the first number of the obtained
+// array or slice not following the direction, otherwise -1.
+func nonMonotonicIndex(obtained any, direction int, strict bool) (int, error) {
+	value := reflect.ValueOf(obtained)
+	kind := value.Kind()
+	if kind != reflect.Array && kind != reflect.Slice {
+		return 0, fmt.Errorf("obtained %s is no array or slice", ValueDescription(obtained))
+	}
+	for i := 1; i < value.Len(); i++ {
+		cmp, ok := compareNumbers(value.Index(i), value.Index(i-1))
+		if !ok {
+			if !isNumber(value.Index(i)) {
+				return 0, fmt.Errorf("element %d is no number", i)
+			}
+			return i, nil
+		}
+		if cmp == -direction || (strict && cmp == 0) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// EOF
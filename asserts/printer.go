@@ -15,6 +15,10 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 )
 
 //--------------------
@@ -63,54 +67,203 @@ const (
 	Fail
 	OK
 	NotOK
+	HasKey
+	Group
+	EqualWith
+	Golden
+	JSONEqual
+	JSONPath
+	Eventually
+	Consistently
+	ErrorIs
+	ErrorAs
+	Snapshot
+	Sorted
+	ContainsAll
+	ContainsAny
+	ElementsMatch
+	Subset
+	Unique
+	HasValue
+	KeysEqual
+	MapSubset
+	TimeEqual
+	WithinDuration
+	Before
+	After
+	SameSecond
+	SameMinute
+	SameDay
+	AboutRel
+	AboutULP
+	Positive
+	Negative
+	NaN
+	Inf
+	Increasing
+	Decreasing
+	HasPrefix
+	HasSuffix
+	EqualFold
+	EqualNormalized
+	GoroutineLeak
+	Receive
+	NoReceive
+	Closed
+	Receivable
+	Buffered
+	PanicsMatching
+	PanicsWithError
+	ContainsMatch
 )
 
 // testNames maps the tests to their descriptive names.
 var testNames = []string{
-	Invalid:      "invalid",
-	True:         "true",
-	False:        "false",
-	Nil:          "nil",
-	NotNil:       "not nil",
-	Zero:         "zero",
-	NoError:      "no error",
-	AnyError:     "any error",
-	Equal:        "equal",
-	Different:    "different",
-	Contains:     "contains",
-	NotContains:  "not contains",
-	About:        "about",
-	Range:        "range",
-	Substring:    "substring",
-	Case:         "case",
-	Match:        "match",
-	ErrorMatch:   "error match",
-	Implementor:  "implementor",
-	Assignable:   "assignable",
-	Unassignable: "unassignable",
-	Empty:        "empty",
-	NotEmpty:     "not empty",
-	Length:       "length",
-	Panics:       "panics",
-	NotPanics:    "not panics",
-	PanicsWith:   "panics with",
-	PathExists:   "path exists",
-	Wait:         "wait",
-	WaitClosed:   "wait closed",
-	WaitGroup:    "wait group",
-	WaitTested:   "wait tested",
-	Retry:        "retry",
-	Fail:         "fail",
+	Invalid:         "invalid",
+	True:            "true",
+	False:           "false",
+	Nil:             "nil",
+	NotNil:          "not nil",
+	Zero:            "zero",
+	NoError:         "no error",
+	AnyError:        "any error",
+	Equal:           "equal",
+	Different:       "different",
+	Contains:        "contains",
+	NotContains:     "not contains",
+	About:           "about",
+	Range:           "range",
+	Substring:       "substring",
+	Case:            "case",
+	Match:           "match",
+	ErrorMatch:      "error match",
+	ErrorContains:   "error contains",
+	Implementor:     "implementor",
+	Assignable:      "assignable",
+	Unassignable:    "unassignable",
+	Empty:           "empty",
+	NotEmpty:        "not empty",
+	Length:          "length",
+	Panics:          "panics",
+	NotPanics:       "not panics",
+	PanicsWith:      "panics with",
+	PathExists:      "path exists",
+	Wait:            "wait",
+	WaitClosed:      "wait closed",
+	WaitGroup:       "wait group",
+	WaitTested:      "wait tested",
+	Retry:           "retry",
+	Fail:            "fail",
+	OK:              "ok",
+	NotOK:           "not ok",
+	HasKey:          "has key",
+	Group:           "group",
+	EqualWith:       "equal with",
+	Golden:          "golden",
+	JSONEqual:       "json equal",
+	JSONPath:        "json path",
+	Eventually:      "eventually",
+	Consistently:    "consistently",
+	ErrorIs:         "error is",
+	ErrorAs:         "error as",
+	Snapshot:        "snapshot",
+	Sorted:          "sorted",
+	ContainsAll:     "contains all",
+	ContainsAny:     "contains any",
+	ElementsMatch:   "elements match",
+	Subset:          "subset",
+	Unique:          "unique",
+	HasValue:        "has value",
+	KeysEqual:       "keys equal",
+	MapSubset:       "map subset",
+	TimeEqual:       "time equal",
+	WithinDuration:  "within duration",
+	Before:          "before",
+	After:           "after",
+	SameSecond:      "same second",
+	SameMinute:      "same minute",
+	SameDay:         "same day",
+	AboutRel:        "about relative",
+	AboutULP:        "about ulp",
+	Positive:        "positive",
+	Negative:        "negative",
+	NaN:             "nan",
+	Inf:             "inf",
+	Increasing:      "increasing",
+	Decreasing:      "decreasing",
+	HasPrefix:       "has prefix",
+	HasSuffix:       "has suffix",
+	EqualFold:       "equal fold",
+	EqualNormalized: "equal normalized",
+	GoroutineLeak:   "goroutine leak",
+	Receive:         "receive",
+	NoReceive:       "no receive",
+	Closed:          "closed",
+	Receivable:      "receivable",
+	Buffered:        "buffered",
+	PanicsMatching:  "panics matching",
+	PanicsWithError: "panics with error",
+	ContainsMatch:   "contains match",
 }
 
 // String implements fmt.Stringer.
 func (t Test) String() string {
-	if int(t) < len(testNames) {
+	testsMu.RLock()
+	defer testsMu.RUnlock()
+	if t > 0 && int(t) < len(testNames) {
 		return testNames[t]
 	}
 	return "invalid"
 }
 
+// ObexFormatter formats the obtained and expected values of a failed
+// custom test for the output.
+type ObexFormatter func(obtained, expected any) string
+
+var (
+	// testsMu protects the tests registered at runtime.
+	testsMu sync.RWMutex
+
+	// testFormatters maps the registered custom tests to their
+	// formatters, which may be nil.
+	testFormatters = map[Test]ObexFormatter{}
+)
+
+// RegisterTest registers a custom test with the given name and returns
+// its code. It is used for own assertions reporting their failures with
+// Asserts.Report(). Registering a known name returns its test.
+func RegisterTest(name string) Test {
+	return RegisterFormattedTest(name, nil)
+}
+
+// RegisterFormattedTest registers a custom test like RegisterTest(). Its
+// obtained and expected values are formatted by the given formatter. A
+// new formatter for an already registered custom test replaces the old one.
+func RegisterFormattedTest(name string, format ObexFormatter) Test {
+	testsMu.Lock()
+	defer testsMu.Unlock()
+	for i, tn := range testNames {
+		if i > 0 && tn == name {
+			test := Test(i)
+			if _, ok := testFormatters[test]; ok && format != nil {
+				testFormatters[test] = format
+			}
+			return test
+		}
+	}
+	testNames = append(testNames, name)
+	test := Test(len(testNames) - 1)
+	testFormatters[test] = format
+	return test
+}
+
+// testFormatter returns the formatter of a registered test or nil.
+func testFormatter(test Test) ObexFormatter {
+	testsMu.RLock()
+	defer testsMu.RUnlock()
+	return testFormatters[test]
+}
+
 //--------------------
 // PRINTER
 //--------------------
@@ -167,44 +320,176 @@ func (p *standardPrinter) Errorf(format string, args ...any) {
 	fmt.Fprintf(os.Stderr, format, args...)
 }
 
-// BufferedPrinter collects prints to be retrieved later via Flush().
+// PrintLevel tells if a print has been a log or an error.
+type PrintLevel int
+
+// Levels of prints.
+const (
+	LogLevel PrintLevel = iota
+	ErrorLevel
+)
+
+// String implements fmt.Stringer.
+func (l PrintLevel) String() string {
+	if l == ErrorLevel {
+		return "ERR"
+	}
+	return "LOG"
+}
+
+// BufferedEntry is one print collected by a BufferedPrinter.
+type BufferedEntry struct {
+	Level     PrintLevel
+	Timestamp time.Time
+	Text      string
+}
+
+// String implements fmt.Stringer.
+func (e BufferedEntry) String() string {
+	return "[" + e.Level.String() + "] " + e.Text
+}
+
+// BufferedPrinter collects prints to be retrieved later via Flush()
+// or to be queried. It is safe for concurrent use, so tests can check
+// what has been printed by asserts in background goroutines.
 type BufferedPrinter interface {
 	Printer
 
 	// Flush returns and resets the buffered prints.
 	Flush() []string
+
+	// Entries returns all buffered entries.
+	Entries() []BufferedEntry
+
+	// Logs returns the buffered entries printed with Logf().
+	Logs() []BufferedEntry
+
+	// Errors returns the buffered entries printed with Errorf().
+	Errors() []BufferedEntry
+
+	// Grep returns the buffered entries with a text matching
+	// the regular expression.
+	Grep(regex string) ([]BufferedEntry, error)
+
+	// WaitFor waits until an entry with a text matching the regular
+	// expression is buffered and returns it. An error is returned if
+	// there is none before the timeout.
+	WaitFor(regex string, timeout time.Duration) (BufferedEntry, error)
 }
 
 // bufferedPrinter collects the prints which can be retrieved later.
 type bufferedPrinter struct {
-	buffer []string
+	mu      sync.Mutex
+	entries []BufferedEntry
+	changed chan struct{}
 }
 
 // NewBufferedPrinter returns the buffered printer for collecting
 // assertion output.
 func NewBufferedPrinter() BufferedPrinter {
-	return &bufferedPrinter{}
+	return &bufferedPrinter{
+		changed: make(chan struct{}),
+	}
 }
 
 // Logf implements Printer.
 func (p *bufferedPrinter) Logf(format string, args ...any) {
-	s := fmt.Sprintf("[LOG] "+format, args...)
-	p.buffer = append(p.buffer, s)
+	p.add(LogLevel, fmt.Sprintf(format, args...))
 }
 
 // Errorf implements Printer.
 func (p *bufferedPrinter) Errorf(format string, args ...any) {
-	s := fmt.Sprintf("[ERR] "+format, args...)
-	p.buffer = append(p.buffer, s)
+	p.add(ErrorLevel, fmt.Sprintf(format, args...))
 }
 
 // Flush implements BufferedPrinter.
 func (p *bufferedPrinter) Flush() []string {
-	b := p.buffer
-	p.buffer = nil
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var b []string
+	for _, entry := range p.entries {
+		b = append(b, entry.String())
+	}
+	p.entries = nil
 	return b
 }
 
+// Entries implements BufferedPrinter.
+func (p *bufferedPrinter) Entries() []BufferedEntry {
+	return p.filter(func(BufferedEntry) bool { return true })
+}
+
+// Logs implements BufferedPrinter.
+func (p *bufferedPrinter) Logs() []BufferedEntry {
+	return p.filter(func(e BufferedEntry) bool { return e.Level == LogLevel })
+}
+
+// Errors implements BufferedPrinter.
+func (p *bufferedPrinter) Errors() []BufferedEntry {
+	return p.filter(func(e BufferedEntry) bool { return e.Level == ErrorLevel })
+}
+
+// Grep implements BufferedPrinter.
+func (p *bufferedPrinter) Grep(regex string) ([]BufferedEntry, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return nil, err
+	}
+	return p.filter(func(e BufferedEntry) bool { return re.MatchString(e.Text) }), nil
+}
+
+// WaitFor implements BufferedPrinter.
+func (p *bufferedPrinter) WaitFor(regex string, timeout time.Duration) (BufferedEntry, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return BufferedEntry{}, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		p.mu.Lock()
+		for _, entry := range p.entries {
+			if re.MatchString(entry.Text) {
+				p.mu.Unlock()
+				return entry, nil
+			}
+		}
+		changed := p.changed
+		p.mu.Unlock()
+		select {
+		case <-changed:
+		case <-timer.C:
+			return BufferedEntry{}, fmt.Errorf("no entry matching %q printed within %v", regex, timeout)
+		}
+	}
+}
+
+// add appends an entry and notifies the waiting.
+func (p *bufferedPrinter) add(level PrintLevel, text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = append(p.entries, BufferedEntry{
+		Level:     level,
+		Timestamp: time.Now(),
+		Text:      text,
+	})
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// filter returns a copy of the entries accepted by the function.
+func (p *bufferedPrinter) filter(accept func(e BufferedEntry) bool) []BufferedEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := []BufferedEntry{}
+	for _, entry := range p.entries {
+		if accept(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
 //--------------------
 // HELPER
 //--------------------
@@ -227,6 +512,34 @@ func ValueDescription(value any) string {
 	}
 }
 
+// ErrorChain returns the chain of wrapped errors as one line per error
+// containing its type and message with escaped newlines. Joined errors
+// like those of errors.Join() are shown indented below the joining one.
+func ErrorChain(err error) string {
+	return strings.Join(errorChain(err, ""), "\n")
+}
+
+// errorChain walks the wrapped errors and returns their lines.
+func errorChain(err error, indent string) []string {
+	lines := []string{}
+	for err != nil {
+		msg := strings.ReplaceAll(err.Error(), "\n", `\n`)
+		lines = append(lines, fmt.Sprintf("%s%T: %s", indent, err, msg))
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, joined := range e.Unwrap() {
+				lines = append(lines, errorChain(joined, indent+"  ")...)
+			}
+			return lines
+		default:
+			return lines
+		}
+	}
+	return lines
+}
+
 // TypedValue returns a value including its type.
 func TypedValue(value any) string {
 	kind := reflect.ValueOf(value).Kind()
@@ -0,0 +1,133 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//--------------------
+// JSON REPORT
+//--------------------
+
+// jsonFailure is the JSON representation of a failure detail.
+type jsonFailure struct {
+	Timestamp string   `json:"timestamp"`
+	Location  string   `json:"location"`
+	Function  string   `json:"function"`
+	Test      string   `json:"test"`
+	Error     string   `json:"error"`
+	Message   string   `json:"message,omitempty"`
+	Obtained  string   `json:"obtained"`
+	Expected  string   `json:"expected"`
+	Diff      []string `json:"diff,omitempty"`
+}
+
+// WriteJSON writes the details of the failures as JSON lines, one
+// object per failure, e.g. for feeding them into log processing. The
+// obtained and expected values are written formatted as strings.
+func WriteJSON(w io.Writer, failures Failures) error {
+	encoder := json.NewEncoder(w)
+	for _, detail := range failures.Details() {
+		location, fun := detail.Location()
+		jf := jsonFailure{
+			Timestamp: detail.Timestamp().Format(time.RFC3339Nano),
+			Location:  location,
+			Function:  fun,
+			Test:      detail.Test().String(),
+			Error:     detail.Error().Error(),
+			Message:   detail.Message(),
+			Obtained:  fmt.Sprintf("%v", detail.Obtained()),
+			Expected:  fmt.Sprintf("%v", detail.Expected()),
+			Diff:      detail.Diff(),
+		}
+		if err := encoder.Encode(jf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//--------------------
+// JUNIT REPORT
+//--------------------
+
+// junitSuite is the JUnit XML representation of all failures.
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+// junitCase is the JUnit XML representation of one failure.
+type junitCase struct {
+	Name      string       `xml:"name,attr"`
+	Classname string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+// junitFailure contains the failure message and its details.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// WriteJUnit writes the failures as JUnit XML test suite with the given
+// name, e.g. for CI reporting. Each failure is one failed test case named
+// after its function and location.
+func WriteJUnit(w io.Writer, name string, failures Failures) error {
+	details := failures.Details()
+	suite := junitSuite{
+		Name:      name,
+		Tests:     len(details),
+		Failures:  len(details),
+		Timestamp: time.Now().Format(time.RFC3339),
+		Cases:     []junitCase{},
+	}
+	for _, detail := range details {
+		location, fun := detail.Location()
+		content := detail.Error().Error()
+		if diff := detail.Diff(); len(diff) > 0 {
+			content += "\n" + strings.Join(diff, "\n")
+		}
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      fun + " " + strings.TrimSuffix(location, ":"),
+			Classname: name,
+			Failure: junitFailure{
+				Message: detail.Error().Error(),
+				Type:    detail.Test().String(),
+				Content: content,
+			},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// EOF
@@ -0,0 +1,149 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+//go:build go1.21
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+//--------------------
+// SLOG PRINTER
+//--------------------
+
+// slogPrinter emits the output as records of a structured logger.
+type slogPrinter struct {
+	logger *slog.Logger
+}
+
+// NewSlogPrinter returns a printer emitting Logf() as info and Errorf()
+// as error records of the given logger. The location and the function of
+// the output of the failers are passed as attributes "location" and
+// "function". Failures additionally get the attributes "test", "obtained",
+// "expected", "message", and if computed "diff" like with NewSlogValidation().
+func NewSlogPrinter(logger *slog.Logger) Printer {
+	return &slogPrinter{
+		logger: logger,
+	}
+}
+
+// Logf implements Printer.
+func (p *slogPrinter) Logf(format string, args ...any) {
+	p.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Errorf implements Printer.
+func (p *slogPrinter) Errorf(format string, args ...any) {
+	p.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// printLog implements failurePrinter.
+func (p *slogPrinter) printLog(location, fun, text string) {
+	p.log(slog.LevelInfo, text, slog.String("location", location), slog.String("function", fun))
+}
+
+// printFailure implements failurePrinter.
+func (p *slogPrinter) printFailure(fl *failure, logged bool) {
+	level := slog.LevelError
+	if logged {
+		level = slog.LevelInfo
+	}
+	text := strings.TrimPrefix(fl.String(), fl.location+" ")
+	attrs := []slog.Attr{
+		slog.String("test", fl.test.String()),
+		slog.String("location", fl.location),
+		slog.String("function", fl.fun),
+		slog.Any("obtained", fl.obtained),
+		slog.Any("expected", unwrapExpected(fl.expected)),
+		slog.String("message", fl.message),
+	}
+	if len(fl.lines) > 0 {
+		attrs = append(attrs, slog.Any("diff", fl.lines))
+	}
+	p.log(level, text, attrs...)
+}
+
+// log emits one record.
+func (p *slogPrinter) log(level slog.Level, text string, attrs ...slog.Attr) {
+	text = strings.TrimSuffix(text, "\n")
+	p.logger.LogAttrs(context.Background(), level, text, attrs...)
+}
+
+//--------------------
+// SLOG FAILER
+//--------------------
+
+// slogFailer collects failures like the validation failer and
+// additionally emits them as records of a structured logger.
+type slogFailer struct {
+	validationFailer
+
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// Logf implements Failer.
+func (f *slogFailer) Logf(format string, args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	location, fun := here(f.offset)
+	printLog(f.printer, location, fun, fmt.Sprintf(format, args...))
+}
+
+// Fail implements Failer.
+func (f *slogFailer) Fail(test Test, obtained, expected any, msgs ...string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	location, fun := here(f.offset)
+	detail := newFailureDetail(location, fun, test, obtained, expected, msgs...)
+	f.details = append(f.details, detail)
+	f.errs = append(f.errs, detail.err)
+	attrs := []slog.Attr{
+		slog.String("test", test.String()),
+		slog.String("location", location),
+		slog.String("function", fun),
+		slog.Any("obtained", detail.Obtained()),
+		slog.Any("expected", detail.Expected()),
+		slog.String("message", detail.Message()),
+	}
+	if len(detail.diff) > 0 {
+		attrs = append(attrs, slog.Any("diff", detail.diff))
+	}
+	f.logger.LogAttrs(context.Background(), f.level, detail.err.Error(), attrs...)
+	return false
+}
+
+// NewSlogValidation creates a new Asserts instance which collects
+// validation failures like NewValidation(). Additionally each failure is
+// emitted as record of the given logger with the given level and the
+// attributes "test", "location", "function", "obtained", "expected",
+// "message", and if computed "diff". Logf() emits info records with the
+// attributes "location" and "function".
+func NewSlogValidation(logger *slog.Logger, level slog.Level) (*Asserts, Failures) {
+	sf := &slogFailer{
+		validationFailer: validationFailer{
+			printer: NewSlogPrinter(logger),
+			offset:  4,
+			details: []FailureDetail{},
+			errs:    []error{},
+		},
+		logger: logger,
+		level:  level,
+	}
+	return New(sf), sf
+}
+
+// EOF
@@ -0,0 +1,113 @@
+// Tideland Go Audit - Asserts - Unit Tests
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+//go:build go1.21
+
+package asserts_test
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"tideland.dev/go/audit/asserts"
+)
+
+//--------------------
+// TESTS
+//--------------------
+
+// TestSlogPrinter tests the emitting of printer output as slog records.
+func TestSlogPrinter(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	p := asserts.NewSlogPrinter(logger)
+
+	p.Logf("hello %s\n", "world")
+	p.Errorf("broken")
+
+	assert := asserts.NewTesting(t, asserts.NoFailing)
+	assert.SetPrinter(p)
+	assert.Equal(1, 2, "one", "two")
+	equalLocation := callerLocation(-1)
+	assert.Equal([]int{1, 2}, []int{1, 3})
+
+	records := slogRecords(successfulAssert, buf)
+	successfulAssert.Length(records, 4)
+	successfulAssert.Equal(records[0]["level"], "INFO")
+	successfulAssert.Equal(records[0]["msg"], "hello world")
+	successfulAssert.Nil(records[0]["location"])
+	successfulAssert.Equal(records[1]["level"], "ERROR")
+	successfulAssert.Equal(records[1]["msg"], "broken")
+	successfulAssert.Equal(records[2]["level"], "INFO")
+	successfulAssert.Equal(records[2]["msg"], "assert 'equal' in TestSlogPrinter() failed {got: 1 (int), want: 2 (int), info: one two}")
+	successfulAssert.Equal(records[2]["test"], "equal")
+	successfulAssert.Equal(records[2]["location"], equalLocation)
+	successfulAssert.Equal(records[2]["function"], "TestSlogPrinter")
+	successfulAssert.Equal(records[2]["obtained"], 1.0)
+	successfulAssert.Equal(records[2]["expected"], 2.0)
+	successfulAssert.Equal(records[2]["message"], "one two")
+	successfulAssert.Nil(records[2]["diff"])
+	successfulAssert.Equal(records[3]["diff"], []any{"[1]: got 2, want 3"})
+}
+
+// TestSlogValidation tests the validation emitting its failures as slog records.
+func TestSlogValidation(t *testing.T) {
+	successfulAssert := successfulAsserts(t)
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	assert, failures := asserts.NewSlogValidation(logger, slog.LevelWarn)
+
+	assert.Logf("checking %d", 1)
+	logfLocation := callerLocation(-1)
+	assert.True(true)
+	assert.Equal(1, 2, "one", "two")
+	equalLocation := callerLocation(-1)
+	assert.Equal([]int{1, 2}, []int{1, 3})
+
+	successfulAssert.Length(failures.Details(), 2)
+	records := slogRecords(successfulAssert, buf)
+	successfulAssert.Length(records, 3)
+	successfulAssert.Equal(records[0]["level"], "INFO")
+	successfulAssert.Equal(records[0]["msg"], "checking 1")
+	successfulAssert.Equal(records[0]["location"], logfLocation)
+	successfulAssert.Equal(records[0]["function"], "TestSlogValidation")
+	successfulAssert.Equal(records[1]["level"], "WARN")
+	successfulAssert.Equal(records[1]["msg"], failures.Errors()[0].Error())
+	successfulAssert.Equal(records[1]["test"], "equal")
+	successfulAssert.Equal(records[1]["location"], equalLocation)
+	successfulAssert.Equal(records[1]["function"], "TestSlogValidation")
+	successfulAssert.Equal(records[1]["obtained"], 1.0)
+	successfulAssert.Equal(records[1]["expected"], 2.0)
+	successfulAssert.Equal(records[1]["message"], "one two")
+	successfulAssert.Nil(records[1]["diff"])
+	successfulAssert.Equal(records[2]["diff"], []any{"[1]: got 2, want 3"})
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// slogRecords parses the JSON records written by a slog logger.
+func slogRecords(assert *asserts.Asserts, buf *bytes.Buffer) []map[string]any {
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]any{}
+		assert.NoError(json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+// EOF
@@ -0,0 +1,482 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//--------------------
+// CONSTANTS
+//--------------------
+
+// snapshotDir is the default directory containing the snapshot files.
+const snapshotDir = "__snapshots__"
+
+//--------------------
+// SNAPSHOT
+//--------------------
+
+// MatchSnapshot serializes the obtained value deterministically and compares
+// it with the stored snapshot. Snapshots are stored in the file
+// __snapshots__/<TestName>.snap, keyed by the name of the running test,
+// including sub-tests, and the number of the call inside of it. Without a
+// running test, e.g. in validation mode, the name of the calling function
+// is used. Like golden files they are written in update mode, see Golden().
+//
+// When running inside of a test the snapshots of the test not matched
+// anymore are reported as obsolete at its end, in update mode they are
+// pruned.
+func (a *Asserts) MatchSnapshot(obtained any, msgs ...string) bool {
+	name, cleanup := snapshotTest(a.failer)
+	filename, key, first := a.snapshots.next(name)
+	if first && cleanup != nil {
+		cleanup(func() {
+			a.checkSnapshots(filename, name)
+		})
+	}
+	content := snapshotString(obtained)
+	sf, err := readSnapshotFile(filename)
+	if err != nil {
+		return a.failer.Fail(Snapshot, filename, nil, appendMsg(msgs, "cannot read snapshot file: "+err.Error())...)
+	}
+	expected, ok := sf.contents[key]
+	if isUpdateMode() {
+		if ok && expected == content {
+			return true
+		}
+		sf.set(key, content)
+		if err := sf.write(filename); err != nil {
+			return a.failer.Fail(Snapshot, filename, nil, appendMsg(msgs, "cannot write snapshot file: "+err.Error())...)
+		}
+		a.failer.Logf("snapshot %q in %q updated", key, filename)
+		return true
+	}
+	if !ok {
+		return a.failer.Fail(Snapshot, key, nil, appendMsg(msgs, "snapshot missing in "+filename)...)
+	}
+	if content != expected {
+		return a.failer.Fail(Snapshot, content, expected, msgs...)
+	}
+	return true
+}
+
+// CheckSnapshots reports obsolete snapshots of all tests and functions. These
+// are stored in the snapshot files used so far but have not been matched by
+// the calls of MatchSnapshot(). So it has to be called at the end of a test,
+// e.g. deferred. In update mode the obsolete snapshots are pruned instead.
+// Inside of tests the snapshots of each test are checked automatically.
+func (a *Asserts) CheckSnapshots(msgs ...string) bool {
+	obsolete := []string{}
+	for _, filename := range a.snapshots.filenames() {
+		unused, pruned, err := a.pruneSnapshots(filename, func(key string) bool { return true })
+		if err != nil {
+			return a.failer.Fail(Snapshot, filename, nil, appendMsg(msgs, err.Error())...)
+		}
+		if pruned {
+			a.failer.Logf("obsolete snapshots %v in %q pruned", unused, filename)
+			continue
+		}
+		obsolete = append(obsolete, unused...)
+	}
+	if len(obsolete) > 0 {
+		return a.failer.Fail(Snapshot, obsolete, nil, msgs...)
+	}
+	return true
+}
+
+// SetSnapshotDir sets the directory containing the snapshot files
+// and returns the current one, e.g. for restoring.
+func (a *Asserts) SetSnapshotDir(dir string) string {
+	return a.snapshots.setDir(dir)
+}
+
+// checkSnapshots reports or prunes the obsolete snapshots of one test.
+func (a *Asserts) checkSnapshots(filename, name string) {
+	defer a.snapshots.reset(name)
+	unused, pruned, err := a.pruneSnapshots(filename, func(key string) bool {
+		return snapshotKeyName(key) == name
+	})
+	switch {
+	case err != nil:
+		a.failer.Fail(Snapshot, filename, nil, err.Error())
+	case pruned:
+		a.failer.Logf("obsolete snapshots %v in %q pruned", unused, filename)
+	case len(unused) > 0:
+		a.failer.Fail(Snapshot, unused, nil, "obsolete snapshots of "+name)
+	}
+}
+
+// pruneSnapshots returns the unused snapshots of the file selected by
+// the function. In update mode they are removed, then pruned is true.
+func (a *Asserts) pruneSnapshots(filename string, selected func(key string) bool) ([]string, bool, error) {
+	sf, err := readSnapshotFile(filename)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read snapshot file: %v", err)
+	}
+	unused := []string{}
+	for _, key := range a.snapshots.unused(filename, sf.keys) {
+		if selected(key) {
+			unused = append(unused, key)
+		}
+	}
+	if len(unused) == 0 || !isUpdateMode() {
+		return unused, false, nil
+	}
+	for _, key := range unused {
+		sf.remove(key)
+	}
+	if err := sf.write(filename); err != nil {
+		return nil, false, fmt.Errorf("cannot write snapshot file: %v", err)
+	}
+	return unused, true, nil
+}
+
+//--------------------
+// SNAPSHOTS
+//--------------------
+
+// snapshotTester is implemented by failers knowing the running test.
+type snapshotTester interface {
+	// snapshotTest returns the name of the running test or the calling
+	// function and, if possible, a function registering a cleanup at the
+	// end of the test.
+	snapshotTest() (string, func(func()))
+}
+
+// snapshotTest returns the name of the test and its cleanup registration
+// of the failer. Other failers use the name of the calling function.
+func snapshotTest(f Failer) (string, func(func())) {
+	if st, ok := f.(snapshotTester); ok {
+		return st.snapshotTest()
+	}
+	_, fun := here(4)
+	return fun, nil
+}
+
+// snapshotKeyName returns the test name part of a snapshot key.
+func snapshotKeyName(key string) string {
+	if i := strings.LastIndex(key, " "); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// snapshots tracks the snapshots matched by an Asserts instance.
+type snapshots struct {
+	mu     sync.Mutex
+	dir    string
+	counts map[string]int
+	used   map[string]map[string]bool
+}
+
+// newSnapshots creates an empty snapshot tracking.
+func newSnapshots() *snapshots {
+	return &snapshots{
+		dir:    snapshotDir,
+		counts: make(map[string]int),
+		used:   make(map[string]map[string]bool),
+	}
+}
+
+// setDir sets the snapshot directory and returns the current one.
+func (s *snapshots) setDir(dir string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.dir
+	s.dir = dir
+	return old
+}
+
+// next returns the filename and the key of the next snapshot of
+// the given test or function and if it is its first one.
+func (s *snapshots) next(name string) (string, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	test := strings.Split(strings.Split(name, "/")[0], ".")[0]
+	test = strings.NewReplacer("(", "", ")", "", "*", "").Replace(test)
+	filename := filepath.Join(s.dir, test+".snap")
+	s.counts[name]++
+	key := fmt.Sprintf("%s %d", name, s.counts[name])
+	if s.used[filename] == nil {
+		s.used[filename] = make(map[string]bool)
+	}
+	s.used[filename][key] = true
+	return filename, key, s.counts[name] == 1
+}
+
+// reset lets the counting of the snapshots of the test start again,
+// e.g. when running it multiple times.
+func (s *snapshots) reset(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.counts, name)
+	for _, keys := range s.used {
+		for key := range keys {
+			if snapshotKeyName(key) == name {
+				delete(keys, key)
+			}
+		}
+	}
+}
+
+// filenames returns the sorted names of the used snapshot files.
+func (s *snapshots) filenames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filenames := []string{}
+	for filename := range s.used {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+// unused returns the keys not used for the given file.
+func (s *snapshots) unused(filename string, keys []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unused := []string{}
+	for _, key := range keys {
+		if !s.used[filename][key] {
+			unused = append(unused, key)
+		}
+	}
+	return unused
+}
+
+//--------------------
+// SNAPSHOT FILE
+//--------------------
+
+// snapshotHeaderRE matches the header of a snapshot in a file. It
+// contains the key and the number of the following content lines.
+var snapshotHeaderRE = regexp.MustCompile(`^--- (.+) \(lines: ([0-9]+)\) ---$`)
+
+// snapshotFile contains the snapshots of one file in their order.
+type snapshotFile struct {
+	keys     []string
+	contents map[string]string
+}
+
+// readSnapshotFile reads a snapshot file. A missing file is empty. The
+// content lines of each snapshot are counted in its header, so they are
+// never taken as header themselves.
+func readSnapshotFile(filename string) (*snapshotFile, error) {
+	sf := &snapshotFile{
+		contents: make(map[string]string),
+	}
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return sf, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	no := 0
+	for scanner.Scan() {
+		no++
+		header := scanner.Text()
+		match := snapshotHeaderRE.FindStringSubmatch(header)
+		if match == nil {
+			return nil, fmt.Errorf("invalid snapshot header in line %d: %q", no, header)
+		}
+		count, _ := strconv.Atoi(match[2])
+		lines := make([]string, 0, count)
+		for len(lines) < count && scanner.Scan() {
+			no++
+			lines = append(lines, scanner.Text())
+		}
+		if len(lines) < count {
+			return nil, fmt.Errorf("snapshot %q is truncated", match[1])
+		}
+		sf.set(match[1], strings.Join(lines, "\n"))
+	}
+	return sf, scanner.Err()
+}
+
+// set sets the content of a snapshot.
+func (sf *snapshotFile) set(key, content string) {
+	if _, ok := sf.contents[key]; !ok {
+		sf.keys = append(sf.keys, key)
+	}
+	sf.contents[key] = content
+}
+
+// remove deletes a snapshot.
+func (sf *snapshotFile) remove(key string) {
+	delete(sf.contents, key)
+	for i, k := range sf.keys {
+		if k == key {
+			sf.keys = append(sf.keys[:i], sf.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// write writes the snapshot file.
+func (sf *snapshotFile) write(filename string) error {
+	var buf bytes.Buffer
+	for _, key := range sf.keys {
+		content := sf.contents[key]
+		fmt.Fprintf(&buf, "--- %s (lines: %d) ---\n%s\n", key, strings.Count(content, "\n")+1, content)
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// snapshotString serializes a value deterministically with sorted map
+// keys and without any pointer addresses.
+func snapshotString(value any) string {
+	var b strings.Builder
+	writeSnapshotValue(&b, reflect.ValueOf(value), "", make(map[uintptr]bool))
+	return b.String()
+}
+
+// writeSnapshotValue writes one value with the given indentation.
+func writeSnapshotValue(b *strings.Builder, v reflect.Value, indent string, visited map[uintptr]bool) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) && v.CanInterface() {
+		b.WriteString("time.Time(" + strconv.Quote(v.Interface().(time.Time).Format(time.RFC3339Nano)) + ")")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		if visited[v.Pointer()] {
+			b.WriteString("<cycle>")
+			return
+		}
+		visited[v.Pointer()] = true
+		defer delete(visited, v.Pointer())
+		b.WriteString("&")
+		writeSnapshotValue(b, v.Elem(), indent, visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		writeSnapshotValue(b, v.Elem(), indent, visited)
+	case reflect.Struct:
+		b.WriteString(v.Type().String() + "{")
+		if v.NumField() == 0 {
+			b.WriteString("}")
+			return
+		}
+		b.WriteString("\n")
+		for i := 0; i < v.NumField(); i++ {
+			b.WriteString(indent + "\t" + v.Type().Field(i).Name + ": ")
+			writeSnapshotValue(b, v.Field(i), indent+"\t", visited)
+			b.WriteString(",\n")
+		}
+		b.WriteString(indent + "}")
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteString(v.Type().String() + "{")
+		if v.Len() == 0 {
+			b.WriteString("}")
+			return
+		}
+		b.WriteString("\n")
+		type entry struct {
+			key   string
+			value reflect.Value
+		}
+		entries := []entry{}
+		iter := v.MapRange()
+		for iter.Next() {
+			var kb strings.Builder
+			writeSnapshotValue(&kb, iter.Key(), indent+"\t", visited)
+			entries = append(entries, entry{kb.String(), iter.Value()})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].key < entries[j].key
+		})
+		for _, e := range entries {
+			b.WriteString(indent + "\t" + e.key + ": ")
+			writeSnapshotValue(b, e.value, indent+"\t", visited)
+			b.WriteString(",\n")
+		}
+		b.WriteString(indent + "}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			b.WriteString(v.Type().String() + "(" + strconv.Quote(string(v.Bytes())) + ")")
+			return
+		}
+		b.WriteString(v.Type().String() + "{")
+		if v.Len() == 0 {
+			b.WriteString("}")
+			return
+		}
+		b.WriteString("\n")
+		for i := 0; i < v.Len(); i++ {
+			b.WriteString(indent + "\t")
+			writeSnapshotValue(b, v.Index(i), indent+"\t", visited)
+			b.WriteString(",\n")
+		}
+		b.WriteString(indent + "}")
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		b.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()))
+	default:
+		// Channels, functions, and unsafe pointers only by type.
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteString("<" + v.Type().String() + ">")
+	}
+}
+
+// EOF
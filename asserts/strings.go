@@ -0,0 +1,67 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"strings"
+)
+
+//--------------------
+// STRING
+//--------------------
+
+// HasPrefix tests if the obtained string begins with the prefix.
+func (a *Asserts) HasPrefix(obtained, prefix string, msgs ...string) bool {
+	if !strings.HasPrefix(obtained, prefix) {
+		return a.failer.Fail(HasPrefix, obtained, prefix, msgs...)
+	}
+	return true
+}
+
+// HasSuffix tests if the obtained string ends with the suffix.
+func (a *Asserts) HasSuffix(obtained, suffix string, msgs ...string) bool {
+	if !strings.HasSuffix(obtained, suffix) {
+		return a.failer.Fail(HasSuffix, obtained, suffix, msgs...)
+	}
+	return true
+}
+
+// EqualFold tests if obtained and expected are equal under
+// Unicode case-folding, so the case of the letters is ignored.
+func (a *Asserts) EqualFold(obtained, expected string, msgs ...string) bool {
+	if !strings.EqualFold(obtained, expected) {
+		return a.failer.Fail(EqualFold, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// EqualNormalized tests if obtained and expected are equal after
+// trimming them and reducing all sequences of whitespace, including
+// newlines, to single spaces. So formatting and indentation are ignored.
+func (a *Asserts) EqualNormalized(obtained, expected string, msgs ...string) bool {
+	if normalizeSpace(obtained) != normalizeSpace(expected) {
+		return a.failer.Fail(EqualNormalized, obtained, expected, msgs...)
+	}
+	return true
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// normalizeSpace trims the string and reduces all sequences
+// of whitespace to single spaces.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// EOF
@@ -0,0 +1,292 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//--------------------
+// CONSTANTS
+//--------------------
+
+// PrinterEnv names the environment variable selecting the printer used
+// by NewTesting(), NewPanic(), and NewValidation(). Values are "terminal"
+// for the terminal printer, "color" for the terminal printer always using
+// colors, and "plain" or an empty value for the plain output.
+const PrinterEnv = "AUDIT_PRINTER"
+
+// ANSI escape sequences for the terminal printer.
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+	ansiGray  = "\x1b[90m"
+)
+
+// sourceContext is the number of source lines shown before
+// and after the location of a failure.
+const sourceContext = 2
+
+//--------------------
+// TERMINAL PRINTER
+//--------------------
+
+// terminalPrinter renders the output for terminals and passes
+// it to a wrapped printer.
+type terminalPrinter struct {
+	printer Printer
+	colored bool
+}
+
+// NewTerminalPrinter returns a printer rendering the output for terminals
+// and passing it to the given printer, e.g. a standard printer or a
+// testing.T. Failures get the source lines around their location and
+// multi-line strings are shown side by side. Colors are used if stdout
+// is a terminal and NO_COLOR is not set to a non-empty value or if AUDIT_PRINTER is "color".
+func NewTerminalPrinter(p Printer) Printer {
+	return &terminalPrinter{
+		printer: p,
+		colored: useColors(),
+	}
+}
+
+// Logf implements Printer.
+func (p *terminalPrinter) Logf(format string, args ...any) {
+	p.printer.Logf(format, args...)
+}
+
+// Errorf implements Printer.
+func (p *terminalPrinter) Errorf(format string, args ...any) {
+	p.printer.Errorf(format, args...)
+}
+
+// printLog implements failurePrinter.
+func (p *terminalPrinter) printLog(location, fun, text string) {
+	p.printer.Logf("%s %s(): %s\n", p.paint(ansiCyan, location), fun, text)
+}
+
+// printFailure implements failurePrinter.
+func (p *terminalPrinter) printFailure(fl *failure, logged bool) {
+	text := p.render(fl)
+	if logged {
+		p.printer.Logf("%s", text)
+		return
+	}
+	p.printer.Errorf("%s", text)
+}
+
+// render renders a failure.
+func (p *terminalPrinter) render(fl *failure) string {
+	var b strings.Builder
+	b.WriteString(p.paint(ansiCyan, fl.location) + " ")
+	b.WriteString(p.paint(ansiBold, fl.header()) + " {")
+	for i, field := range fl.fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if field.name != "" {
+			b.WriteString(field.name + ": ")
+		}
+		switch field.role {
+		case obtainedRole:
+			b.WriteString(p.paint(ansiRed, field.value))
+		case expectedRole:
+			b.WriteString(p.paint(ansiGreen, field.value))
+		default:
+			b.WriteString(field.value)
+		}
+	}
+	b.WriteString("}\n")
+	// Diff or detail lines.
+	for _, line := range fl.lines {
+		trimmed := strings.TrimLeft(line, "\t ")
+		switch {
+		case strings.HasPrefix(trimmed, "@@"):
+			b.WriteString("\t" + p.paint(ansiCyan, line) + "\n")
+		case strings.HasPrefix(trimmed, "-"):
+			b.WriteString("\t" + p.paint(ansiRed, line) + "\n")
+		case strings.HasPrefix(trimmed, "+"):
+			b.WriteString("\t" + p.paint(ansiGreen, line) + "\n")
+		default:
+			b.WriteString("\t" + line + "\n")
+		}
+	}
+	// Multi-line strings side by side.
+	if got, want, ok := multiLineValues(fl.obtained, fl.expected); ok {
+		b.WriteString(p.sideBySide(got, want))
+	}
+	// Source lines around the location.
+	b.WriteString(p.source(fl.location))
+	return b.String()
+}
+
+// sideBySide renders two multi-line strings in two columns.
+func (p *terminalPrinter) sideBySide(got, want string) string {
+	gls := strings.Split(got, "\n")
+	wls := strings.Split(want, "\n")
+	width := len("got")
+	for _, gl := range gls {
+		if w := utf8.RuneCountInString(gl); w > width {
+			width = w
+		}
+	}
+	var b strings.Builder
+	row := func(marker, left, right string, differs bool) {
+		pad := strings.Repeat(" ", width-utf8.RuneCountInString(left))
+		if differs {
+			left = p.paint(ansiRed, left)
+			right = p.paint(ansiGreen, right)
+		}
+		fmt.Fprintf(&b, "\t%s %s%s | %s\n", marker, left, pad, right)
+	}
+	row(" ", "got", "want", false)
+	for i := 0; i < len(gls) || i < len(wls); i++ {
+		var gl, wl string
+		if i < len(gls) {
+			gl = gls[i]
+		}
+		if i < len(wls) {
+			wl = wls[i]
+		}
+		differs := i >= len(gls) || i >= len(wls) || gl != wl
+		marker := " "
+		if differs {
+			marker = "!"
+		}
+		row(marker, gl, wl, differs)
+	}
+	return b.String()
+}
+
+// source returns the source lines around the location.
+func (p *terminalPrinter) source(location string) string {
+	filename, line, ok := findSource(location)
+	if !ok {
+		return ""
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	var b strings.Builder
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if n < line-sourceContext {
+			continue
+		}
+		if n > line+sourceContext {
+			break
+		}
+		text := strings.ReplaceAll(scanner.Text(), "\t", "    ")
+		if n == line {
+			b.WriteString("\t" + p.paint(ansiBold, fmt.Sprintf("> %4d | %s", n, text)) + "\n")
+		} else {
+			b.WriteString("\t" + p.paint(ansiGray, fmt.Sprintf("  %4d | %s", n, text)) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// paint colors the text if wanted.
+func (p *terminalPrinter) paint(color, text string) string {
+	if !p.colored || text == "" {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// findSource finds the full filename of a location by looking
+// for it in the current call stack.
+func findSource(location string) (string, int, bool) {
+	parts := strings.Split(location, ":")
+	if len(parts) < 2 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, false
+	}
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if path.Base(frame.File) == parts[0] && frame.Line == line {
+			return frame.File, line, true
+		}
+		if !more {
+			return "", 0, false
+		}
+	}
+}
+
+// multiLineValues returns the obtained and expected values if both
+// are strings and at least one of them has multiple lines.
+func multiLineValues(obtained, expected any) (string, string, bool) {
+	got, ok := obtained.(string)
+	if !ok {
+		return "", "", false
+	}
+	want, ok := expected.(string)
+	if !ok {
+		return "", "", false
+	}
+	if !strings.Contains(got, "\n") && !strings.Contains(want, "\n") {
+		return "", "", false
+	}
+	return got, want, true
+}
+
+// useColors checks if the terminal printer shall use colors.
+func useColors() bool {
+	switch os.Getenv(PrinterEnv) {
+	case "color":
+		return true
+	case "plain":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// defaultPrinter returns the passed printer or a terminal printer
+// wrapping it, depending on the environment.
+func defaultPrinter(p Printer) Printer {
+	switch os.Getenv(PrinterEnv) {
+	case "terminal", "color":
+		return NewTerminalPrinter(p)
+	}
+	return p
+}
+
+// EOF
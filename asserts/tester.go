@@ -13,12 +13,16 @@ package asserts // import "tideland.dev/go/audit/asserts"
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 )
@@ -104,34 +108,6 @@ func isAbout(obtained, expected, extent float64) bool {
 func isInRange(obtained, low, high any) (bool, error) {
 	// First standard types.
 	switch o := obtained.(type) {
-	case byte:
-		l, lok := low.(byte)
-		h, hok := high.(byte)
-		if !lok && !hok {
-			return false, errors.New("low and/or high are no byte")
-		}
-		return l <= o && o <= h, nil
-	case int:
-		l, lok := low.(int)
-		h, hok := high.(int)
-		if !lok && !hok {
-			return false, errors.New("low and/or high are no int")
-		}
-		return l <= o && o <= h, nil
-	case float64:
-		l, lok := low.(float64)
-		h, hok := high.(float64)
-		if !lok && !hok {
-			return false, errors.New("low and/or high are no float64")
-		}
-		return l <= o && o <= h, nil
-	case rune:
-		l, lok := low.(rune)
-		h, hok := high.(rune)
-		if !lok && !hok {
-			return false, errors.New("low and/or high are no rune")
-		}
-		return l <= o && o <= h, nil
 	case string:
 		l, lok := low.(string)
 		h, hok := high.(string)
@@ -155,6 +131,18 @@ func isInRange(obtained, low, high any) (bool, error) {
 		}
 		return l <= o && o <= h, nil
 	}
+	// Now check the numbers of all kinds.
+	ov := reflect.ValueOf(obtained)
+	if isNumber(ov) {
+		lv := reflect.ValueOf(low)
+		hv := reflect.ValueOf(high)
+		if !isNumber(lv) || !isNumber(hv) {
+			return false, errors.New("low and/or high are no numbers")
+		}
+		lcmp, lok := compareNumbers(lv, ov)
+		hcmp, hok := compareNumbers(ov, hv)
+		return lok && hok && lcmp <= 0 && hcmp <= 0, nil
+	}
 	// Now check the collection types.
 	_, ol, err := hasLength(obtained, 0)
 	if err != nil {
@@ -295,6 +283,410 @@ func hasPanic(pf func(), reason any) (ok bool) {
 	return false
 }
 
+// hasKey checks if the obtained map contains the key.
+func hasKey(obtained, key any) (bool, error) {
+	ov := reflect.ValueOf(obtained)
+	if ov.Kind() != reflect.Map {
+		return false, fmt.Errorf("obtained %s is no map", ValueDescription(obtained))
+	}
+	kv := reflect.ValueOf(key)
+	if !kv.IsValid() || !kv.Type().AssignableTo(ov.Type().Key()) {
+		return false, fmt.Errorf("key %s is no %v", ValueDescription(key), ov.Type().Key())
+	}
+	return ov.MapIndex(kv).IsValid(), nil
+}
+
+// hasValue checks if the obtained map contains the value for any key.
+func hasValue(obtained, value any) (bool, error) {
+	ov := reflect.ValueOf(obtained)
+	if ov.Kind() != reflect.Map {
+		return false, fmt.Errorf("obtained %s is no map", ValueDescription(obtained))
+	}
+	iter := ov.MapRange()
+	for iter.Next() {
+		if reflect.DeepEqual(iter.Value().Interface(), value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// elementsOf returns the elements of an array or slice.
+func elementsOf(value any) ([]any, error) {
+	rv := reflect.ValueOf(value)
+	kind := rv.Kind()
+	if kind != reflect.Array && kind != reflect.Slice {
+		return nil, fmt.Errorf("%s is no array or slice", ValueDescription(value))
+	}
+	elements := make([]any, rv.Len())
+	for i := range elements {
+		elements[i] = rv.Index(i).Interface()
+	}
+	return elements, nil
+}
+
+// unmatchedElements compares the elements of the obtained and expected
+// arrays or slices. It returns the expected elements missing in obtained
+// and the obtained elements not expected. If counted is true each element
+// only matches once, so the number of equal elements is compared too.
+func unmatchedElements(obtained, expected any, counted bool) ([]any, []any, error) {
+	oes, err := elementsOf(obtained)
+	if err != nil {
+		return nil, nil, err
+	}
+	ees, err := elementsOf(expected)
+	if err != nil {
+		return nil, nil, err
+	}
+	matched := make([]bool, len(oes))
+	missing := []any{}
+	for _, ee := range ees {
+		found := false
+		for i, oe := range oes {
+			if counted && matched[i] {
+				continue
+			}
+			if reflect.DeepEqual(oe, ee) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, ee)
+		}
+	}
+	extra := []any{}
+	for i, oe := range oes {
+		if matched[i] {
+			continue
+		}
+		if !counted && containsValue(ees, oe) {
+			continue
+		}
+		extra = append(extra, oe)
+	}
+	return missing, extra, nil
+}
+
+// containsValue checks if the value is one of the elements.
+func containsValue(elements []any, value any) bool {
+	for _, e := range elements {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicateElements returns one line per element of the obtained array
+// or slice which is equal to a former one.
+func duplicateElements(obtained any) ([]string, error) {
+	oes, err := elementsOf(obtained)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := []string{}
+	for i := 1; i < len(oes); i++ {
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(oes[i], oes[j]) {
+				duplicates = append(duplicates, fmt.Sprintf("duplicate: [%d] %v equals [%d]", i, oes[i], j))
+				break
+			}
+		}
+	}
+	return duplicates, nil
+}
+
+// unsortedIndex returns the index of the first element of the obtained
+// slice or array which is less than its predecessor, otherwise -1.
+func unsortedIndex(obtained any) (int, error) {
+	value := reflect.ValueOf(obtained)
+	kind := value.Kind()
+	if kind != reflect.Array && kind != reflect.Slice {
+		return 0, errors.New("obtained value is no array or slice")
+	}
+	for i := 1; i < value.Len(); i++ {
+		less, err := isLess(value.Index(i), value.Index(i-1))
+		if err != nil {
+			return 0, err
+		}
+		if less {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// isLess checks if the value x is less than the value y.
+func isLess(x, y reflect.Value) (bool, error) {
+	switch x.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return x.Int() < y.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return x.Uint() < y.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return x.Float() < y.Float(), nil
+	case reflect.String:
+		return x.String() < y.String(), nil
+	}
+	return false, fmt.Errorf("elements of kind %v cannot be ordered", x.Kind())
+}
+
+// isErrorTarget checks if target is a valid target for errors.As().
+func isErrorTarget(target any) error {
+	value := reflect.ValueOf(target)
+	if !value.IsValid() || value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("target must be a non-nil pointer")
+	}
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	elemType := value.Type().Elem()
+	if elemType.Kind() != reflect.Interface && !elemType.Implements(errorType) {
+		return errors.New("target must be a pointer to an interface or to a type implementing error")
+	}
+	return nil
+}
+
+// pollResult describes the result of a polling assertion.
+type pollResult struct {
+	attempts int
+	elapsed  time.Duration
+	reason   string
+	err      error
+}
+
+// String implements fmt.Stringer.
+func (pr *pollResult) String() string {
+	if pr.err == nil {
+		return fmt.Sprintf("no error after %v and %d attempt(s)", pr.elapsed, pr.attempts)
+	}
+	return fmt.Sprintf("%s after %v and %d attempt(s), last error: %v", pr.reason, pr.elapsed, pr.attempts, pr.err)
+}
+
+// pollEventually calls the function until it returns no error, the
+// timeout is reached, or the context is done.
+func pollEventually(ctx context.Context, cf func() error, timeout, interval time.Duration) *pollResult {
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	pr := &pollResult{}
+	start := time.Now()
+	done := time.NewTimer(timeout)
+	defer done.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		pr.attempts++
+		pr.err = cf()
+		pr.elapsed = time.Since(start)
+		if pr.err == nil {
+			return pr
+		}
+		select {
+		case <-ticker.C:
+		case <-done.C:
+			pr.reason = "timeout"
+			return pr
+		case <-ctx.Done():
+			pr.reason = ctx.Err().Error()
+			pr.elapsed = time.Since(start)
+			return pr
+		}
+	}
+}
+
+// pollConsistently calls the function until the duration is reached,
+// it returns an error, or the context is done.
+func pollConsistently(ctx context.Context, cf func() error, duration, interval time.Duration) *pollResult {
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	pr := &pollResult{}
+	start := time.Now()
+	done := time.NewTimer(duration)
+	defer done.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		pr.attempts++
+		pr.err = cf()
+		pr.elapsed = time.Since(start)
+		if pr.err != nil {
+			pr.reason = "failed"
+			return pr
+		}
+		select {
+		case <-ticker.C:
+		case <-done.C:
+			pr.elapsed = time.Since(start)
+			return pr
+		case <-ctx.Done():
+			pr.reason = ctx.Err().Error()
+			pr.err = ctx.Err()
+			pr.elapsed = time.Since(start)
+			return pr
+		}
+	}
+}
+
+// receiveSignal receives a signal from the channel. It returns
+// false if the context is done before.
+func receiveSignal(ctx context.Context, sigc <-chan any) (any, bool) {
+	select {
+	case obtained := <-sigc:
+		return obtained, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// waitClosed waits until the channel is closed. It returns
+// false if the context is done before.
+func waitClosed(ctx context.Context, sigc <-chan any) bool {
+	for {
+		select {
+		case _, ok := <-sigc:
+			if !ok {
+				// Only return true if channel has been closed.
+				return true
+			}
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// waitGroupWatchers contains the channels closed when the watched
+// wait groups are done.
+var (
+	waitGroupMu       sync.Mutex
+	waitGroupWatchers = map[*sync.WaitGroup]chan struct{}{}
+)
+
+// waitGroup waits until the wait group is done. It returns false if
+// the context is done before. Waiting for a wait group cannot be
+// interrupted, so one watching goroutine per group stays parked in
+// wg.Wait() until the group finishes. Further waits for the same
+// group share it instead of starting a new one.
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) bool {
+	waitGroupMu.Lock()
+	donec, ok := waitGroupWatchers[wg]
+	if !ok {
+		donec = make(chan struct{})
+		waitGroupWatchers[wg] = donec
+		go func() {
+			wg.Wait()
+			waitGroupMu.Lock()
+			delete(waitGroupWatchers, wg)
+			waitGroupMu.Unlock()
+			close(donec)
+		}()
+	}
+	waitGroupMu.Unlock()
+	select {
+	case <-donec:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// retry calls the function until it returns true or the number of
+// retries is reached, pausing between the calls. It returns the
+// number of done calls and false if the context is done before.
+func retry(ctx context.Context, rf func() bool, retries int, pause time.Duration) (int, bool) {
+	timer := time.NewTimer(pause)
+	timer.Stop()
+	defer timer.Stop()
+	for r := 0; r < retries; r++ {
+		if rf() {
+			return r + 1, true
+		}
+		timer.Reset(pause)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return r + 1, false
+		}
+	}
+	return retries, false
+}
+
+// contextReason tells why the context is done, cancelled or
+// deadline exceeded, and how long has been waited.
+func contextReason(ctx context.Context, start time.Time) string {
+	return fmt.Sprintf("%v after %v", context.Cause(ctx), time.Since(start))
+}
+
+// PanicInfo describes a recovered panic. It is the obtained value
+// of a failed NotPanics() assertion.
+type PanicInfo struct {
+	// Value is the value passed to panic().
+	Value any
+
+	// Stack is the stack of the panicking goroutine starting
+	// at the function calling panic().
+	Stack string
+}
+
+// String implements fmt.Stringer.
+func (pi *PanicInfo) String() string {
+	return fmt.Sprintf("%v", pi.Value)
+}
+
+// reason returns the panic value as string, the
+// message in case of an error.
+func (pi *PanicInfo) reason() string {
+	if err, ok := pi.Value.(error); ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("%v", pi.Value)
+}
+
+// topFrame returns the function and the file position of
+// the first frame of the stack.
+func (pi *PanicInfo) topFrame() string {
+	lines := strings.Split(pi.Stack, "\n")
+	for i := 1; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "\t") {
+			continue
+		}
+		position := strings.TrimSpace(lines[i])
+		if j := strings.LastIndex(position, " +0x"); j >= 0 {
+			position = position[:j]
+		}
+		return lines[i-1] + " at " + filepath.Base(position)
+	}
+	return "unknown frame"
+}
+
+// recoverPanic calls the passed function and returns the
+// recovered panic or nil if it does not panic.
+func recoverPanic(pf func()) (pi *PanicInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			pi = &PanicInfo{
+				Value: r,
+				Stack: panicStack(debug.Stack()),
+			}
+		}
+	}()
+	pf()
+	return nil
+}
+
+// panicStack removes the frames of the stack capturing and
+// the panic itself so that it starts at the panicking function.
+func panicStack(stack []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic(") && i+2 <= len(lines) {
+			return strings.Join(append(lines[:1], lines[i+2:]...), "\n")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // isValidPath checks if the given directory or file path exists.
 func isValidPath(path string) (bool, error) {
 	_, err := os.Stat(path)
@@ -0,0 +1,153 @@
+// Tideland Go Audit - Asserts
+//
+// Copyright (C) 2012-2023 Frank Mueller / Tideland / Oldenburg / Germany
+//
+// All rights reserved. Use of this source code is governed
+// by the new BSD license.
+
+package asserts // import "tideland.dev/go/audit/asserts"
+
+//--------------------
+// IMPORTS
+//--------------------
+
+import (
+	"fmt"
+	"time"
+)
+
+//--------------------
+// TIME
+//--------------------
+
+// TimeEqual tests if obtained and expected are the same instant. Other
+// than Equal() it ignores the locations and monotonic clock readings.
+func (a *Asserts) TimeEqual(obtained, expected time.Time, msgs ...string) bool {
+	if !obtained.Equal(expected) {
+		return a.failer.Fail(TimeEqual, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// WithinDuration tests if obtained differs from expected by
+// at most the delta.
+func (a *Asserts) WithinDuration(obtained, expected time.Time, delta time.Duration, msgs ...string) bool {
+	diff := obtained.Sub(expected)
+	if diff < 0 {
+		diff = -diff
+	}
+	if delta < 0 {
+		delta = -delta
+	}
+	if diff > delta {
+		return a.failer.Fail(WithinDuration, obtained, &timeValue{time: expected, delta: delta}, msgs...)
+	}
+	return true
+}
+
+// Before tests if obtained is before expected.
+func (a *Asserts) Before(obtained, expected time.Time, msgs ...string) bool {
+	if !obtained.Before(expected) {
+		return a.failer.Fail(Before, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// After tests if obtained is after expected.
+func (a *Asserts) After(obtained, expected time.Time, msgs ...string) bool {
+	if !obtained.After(expected) {
+		return a.failer.Fail(After, obtained, expected, msgs...)
+	}
+	return true
+}
+
+// SameSecond tests if obtained and expected are in the same second
+// in the given location. A nil location means UTC.
+func (a *Asserts) SameSecond(obtained, expected time.Time, loc *time.Location, msgs ...string) bool {
+	if !isSameTime(obtained, expected, loc, time.Second) {
+		return a.failer.Fail(SameSecond, obtained, &timeValue{time: expected, loc: timeLocation(loc)}, msgs...)
+	}
+	return true
+}
+
+// SameMinute tests if obtained and expected are in the same minute
+// in the given location. A nil location means UTC.
+func (a *Asserts) SameMinute(obtained, expected time.Time, loc *time.Location, msgs ...string) bool {
+	if !isSameTime(obtained, expected, loc, time.Minute) {
+		return a.failer.Fail(SameMinute, obtained, &timeValue{time: expected, loc: timeLocation(loc)}, msgs...)
+	}
+	return true
+}
+
+// SameDay tests if obtained and expected are on the same day in the
+// given location. A nil location means UTC.
+func (a *Asserts) SameDay(obtained, expected time.Time, loc *time.Location, msgs ...string) bool {
+	if !isSameTime(obtained, expected, loc, 24*time.Hour) {
+		return a.failer.Fail(SameDay, obtained, &timeValue{time: expected, loc: timeLocation(loc)}, msgs...)
+	}
+	return true
+}
+
+//--------------------
+// HELPER
+//--------------------
+
+// timeValue transports the expected time of a time test together
+// with the allowed delta or the location of the comparison.
+type timeValue struct {
+	time  time.Time
+	delta time.Duration
+	loc   *time.Location
+}
+
+// String implements fmt.Stringer.
+func (tv *timeValue) String() string {
+	if tv.loc != nil {
+		return tv.time.In(tv.loc).Format(time.RFC3339Nano) + " in " + tv.loc.String()
+	}
+	return tv.time.Format(time.RFC3339Nano) + " ± " + tv.delta.String()
+}
+
+// timeLocation returns the location or UTC if it is nil.
+func timeLocation(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// isSameTime checks if obtained and expected are inside the same
+// second, minute, or day of the location.
+func isSameTime(obtained, expected time.Time, loc *time.Location, unit time.Duration) bool {
+	loc = timeLocation(loc)
+	obtained = obtained.In(loc)
+	expected = expected.In(loc)
+	oy, om, od := obtained.Date()
+	ey, em, ed := expected.Date()
+	if oy != ey || om != em || od != ed {
+		return false
+	}
+	switch unit {
+	case time.Second:
+		return obtained.Hour() == expected.Hour() &&
+			obtained.Minute() == expected.Minute() &&
+			obtained.Second() == expected.Second()
+	case time.Minute:
+		return obtained.Hour() == expected.Hour() &&
+			obtained.Minute() == expected.Minute()
+	}
+	return true
+}
+
+// timeString formats a time in RFC3339 or any other value with %v.
+func timeString(value any) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *timeValue:
+		return v.String()
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// EOF